@@ -0,0 +1,120 @@
+// Package address parses Chinese addresses such as "北京朝阳望京街10号望京SOHO" into
+// province, city, district, street, number and POI, using an administrative division gazetteer.
+package address
+
+import (
+	"regexp"
+	"strings"
+)
+
+// streetFollowers mark a division-looking short name that is actually part of a road
+// or landmark (e.g. "中山路", "朝阳门").
+var streetFollowers = []string{"路", "街", "大道", "大街", "巷", "胡同", "门", "广场", "公园"}
+
+var (
+	townPattern   = regexp.MustCompile(`^\p{Han}{1,8}?(街道|镇|乡)`)
+	roadPattern   = regexp.MustCompile(`^[\p{Han}A-Za-z0-9]{1,12}?(大道|大街|胡同|路|街|巷|弄)`)
+	numberPattern = regexp.MustCompile(`^[甲乙丙]?[0-9一二三四五六七八九十百零〇]+(-[0-9]+)?(号院|号楼|号|弄)`)
+)
+
+// Address is the normalized form of an address. Divisions use full names (北京市, 朝阳区).
+type Address struct {
+	Raw      string `json:"raw"`
+	Province string `json:"province,omitempty"`
+	City     string `json:"city,omitempty"`
+	District string `json:"district,omitempty"`
+	Street   string `json:"street,omitempty"` // township and road, e.g. 望京街
+	Number   string `json:"number,omitempty"` // house number, e.g. 10号
+	POI      string `json:"poi,omitempty"`    // building or place, e.g. 望京SOHO
+}
+
+// Parse parses an address with the default gazetteer.
+func Parse(text string) Address {
+	return Default().Parse(text)
+}
+
+// Parse parses an address. Short names are expanded ("广东深圳南山" -> 广东省 深圳市 南山区)
+// and missing upper levels are filled in from the gazetteer ("南山区..." -> 广东省 深圳市).
+func (g *Gazetteer) Parse(text string) Address {
+	a := Address{Raw: text}
+	rest := strings.TrimSpace(text)
+
+	// 1. Divisions, top-down; each level is searched within the levels found so far.
+	var province, city, district *Division
+	if d, n := g.match(rest, LevelProvince, nil); n > 0 {
+		province, rest = d, trimSeparators(rest[n:])
+		if d.IsMunicipality() {
+			city = d.Children[0]
+			// "北京市北京市朝阳区": the city may be repeated.
+			if _, n := g.match(rest, LevelCity, province); n > 0 {
+				rest = trimSeparators(rest[n:])
+			}
+		}
+	}
+	if city == nil {
+		if d, n := g.match(rest, LevelCity, province); n > 0 {
+			city, rest = d, trimSeparators(rest[n:])
+		}
+	}
+	scope := city
+	if scope == nil {
+		scope = province
+	}
+	if d, n := g.match(rest, LevelDistrict, scope); n > 0 {
+		district, rest = d, trimSeparators(rest[n:])
+	}
+
+	// 2. Missing upper levels follow from the lower ones.
+	if district != nil && city == nil {
+		city = district.Parent
+	}
+	if city != nil && province == nil {
+		province = city.Parent
+	}
+	if province != nil {
+		a.Province = province.Name
+	}
+	if city != nil {
+		a.City = city.Name
+	}
+	if district != nil {
+		a.District = district.Name
+	}
+
+	// 3. Street, house number and POI.
+	if m := townPattern.FindString(rest); m != "" {
+		a.Street, rest = m, rest[len(m):]
+	}
+	if m := roadPattern.FindString(rest); m != "" {
+		a.Street, rest = a.Street+m, rest[len(m):]
+	}
+	if m := numberPattern.FindString(rest); m != "" {
+		a.Number, rest = m, rest[len(m):]
+	}
+	a.POI = trimSeparators(rest)
+	return a
+}
+
+// String joins the components of the address.
+func (a Address) String() string {
+	var parts []string
+	for _, p := range []string{a.Province, a.City, a.District, a.Street, a.Number, a.POI} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func followsStreet(rest string) bool {
+	for _, f := range streetFollowers {
+		if strings.HasPrefix(rest, f) {
+			return true
+		}
+	}
+	return false
+}
+
+func trimSeparators(s string) string {
+	return strings.TrimLeft(s, " ,，、")
+}
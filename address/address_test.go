@@ -0,0 +1,59 @@
+package address
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want Address
+	}{
+		{"北京市朝阳区望京街10号望京SOHO", Address{
+			Province: "北京市", City: "北京市", District: "朝阳区", Street: "望京街", Number: "10号", POI: "望京SOHO",
+		}},
+		{"广东深圳南山科技园", Address{
+			Province: "广东省", City: "深圳市", District: "南山区", POI: "科技园",
+		}},
+		{"南山区科苑路15号", Address{
+			Province: "广东省", City: "深圳市", District: "南山区", Street: "科苑路", Number: "15号",
+		}},
+		{"浙江省杭州市西湖区北山街道曙光路120号", Address{
+			Province: "浙江省", City: "杭州市", District: "西湖区", Street: "北山街道曙光路", Number: "120号",
+		}},
+		{"长春朝阳区", Address{
+			Province: "吉林省", City: "长春市", District: "朝阳区",
+		}},
+		{"新疆乌鲁木齐天山区", Address{
+			Province: "新疆维吾尔自治区", City: "乌鲁木齐市", District: "天山区",
+		}},
+		{"延边州", Address{Province: "吉林省", City: "延边朝鲜族自治州"}},
+		{"河北区中山路", Address{Province: "天津市", City: "天津市", District: "河北区", Street: "中山路"}},
+		{"中山路88号", Address{Street: "中山路", Number: "88号"}},
+	}
+	for _, tt := range tests {
+		got := Parse(tt.text)
+		tt.want.Raw = tt.text
+		if got != tt.want {
+			t.Errorf("Parse(%q)\n got %+v\nwant %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLoadGazetteer(t *testing.T) {
+	g, err := LoadGazetteer(strings.NewReader("# test\n甲省\n  乙市 丙区 丁县/丁\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := g.Lookup("丁")
+	if len(d) != 1 || d[0].Name != "丁县" || d[0].Parent.Name != "乙市" || d[0].Parent.Parent.Name != "甲省" {
+		t.Fatalf("unexpected lookup %+v", d)
+	}
+	if !g.Contains("丙区") || g.Contains("丙") {
+		t.Errorf("single-character short names must not be derived")
+	}
+	if _, err := LoadGazetteer(strings.NewReader("  乙市\n")); err == nil {
+		t.Errorf("expected error for city before province")
+	}
+}
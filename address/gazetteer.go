@@ -0,0 +1,210 @@
+package address
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//go:embed gazetteer.txt
+var gazetteerData string
+
+// Level is the administrative level of a division.
+type Level int
+
+const (
+	LevelProvince Level = iota + 1 // 省、自治区、直辖市、特别行政区
+	LevelCity                      // 地级市、自治州、地区、盟
+	LevelDistrict                  // 市辖区、县、县级市、旗
+)
+
+// divisionSuffixes are stripped to derive the short name of a division, longest first.
+var divisionSuffixes = []string{
+	"特别行政区", "自治区", "自治州", "自治县", "新区", "林区", "地区", "矿区", "省", "市", "区", "县", "盟",
+}
+
+// Division is a node of the administrative hierarchy.
+type Division struct {
+	Name     string   // full name, e.g. 朝阳区
+	Aliases  []string // short names, e.g. 朝阳
+	Level    Level
+	Parent   *Division
+	Children []*Division
+}
+
+// Short returns the common short name of the division (e.g. 广西 for 广西壮族自治区).
+func (d *Division) Short() string {
+	if len(d.Aliases) > 0 {
+		return d.Aliases[0]
+	}
+	return d.Name
+}
+
+// Within reports whether d is anc or lies below it.
+func (d *Division) Within(anc *Division) bool {
+	for p := d; p != nil; p = p.Parent {
+		if p == anc {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMunicipality reports whether a province-level division consists of a single city
+// of the same name (北京、上海、香港...).
+func (d *Division) IsMunicipality() bool {
+	return d.Level == LevelProvince && len(d.Children) == 1 && d.Children[0].Name == d.Name
+}
+
+// Gazetteer is a province / city / district hierarchy indexed by full and short names.
+type Gazetteer struct {
+	Provinces []*Division
+	index     map[string][]*Division
+	maxLen    int // longest indexed name in runes
+}
+
+var (
+	defaultOnce sync.Once
+	defaultGaz  *Gazetteer
+)
+
+// Default returns the gazetteer embedded in the package. It lists every province and
+// prefecture-level city, but the districts of only the municipalities and some large
+// cities. Elsewhere a district is left in the street ("路北区建设路") and cannot fill in
+// its city; load a complete table with LoadGazetteerFile where that matters.
+func Default() *Gazetteer {
+	defaultOnce.Do(func() {
+		g, err := LoadGazetteer(strings.NewReader(gazetteerData))
+		if err != nil {
+			panic(err)
+		}
+		defaultGaz = g
+	})
+	return defaultGaz
+}
+
+// LoadGazetteerFile loads a gazetteer in the format of the embedded gazetteer.txt.
+func LoadGazetteerFile(path string) (*Gazetteer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadGazetteer(file)
+}
+
+// LoadGazetteer reads a gazetteer. A line without indentation is a province; an indented
+// line is a city followed by its districts. A name may carry its short names as "全称/简称".
+func LoadGazetteer(r io.Reader) (*Gazetteer, error) {
+	g := &Gazetteer{index: make(map[string][]*Division)}
+	var province *Division
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			province = g.add(fields[0], LevelProvince, nil)
+			g.Provinces = append(g.Provinces, province)
+			continue
+		}
+		if province == nil {
+			return nil, fmt.Errorf("line %d: city before any province", lineNo)
+		}
+		city := g.add(fields[0], LevelCity, province)
+		for _, f := range fields[1:] {
+			g.add(f, LevelDistrict, city)
+		}
+	}
+	return g, scanner.Err()
+}
+
+func (g *Gazetteer) add(field string, level Level, parent *Division) *Division {
+	names := strings.Split(field, "/")
+	d := &Division{Name: names[0], Aliases: names[1:], Level: level, Parent: parent}
+	if len(d.Aliases) == 0 {
+		if short := shortName(d.Name); short != d.Name {
+			d.Aliases = []string{short}
+		}
+	}
+	if parent != nil {
+		parent.Children = append(parent.Children, d)
+	}
+	for _, name := range append([]string{d.Name}, d.Aliases...) {
+		g.index[name] = append(g.index[name], d)
+		if n := utf8.RuneCountInString(name); n > g.maxLen {
+			g.maxLen = n
+		}
+	}
+	return d
+}
+
+// shortName strips a division suffix, keeping at least two characters (忠县 stays 忠县).
+func shortName(name string) string {
+	for _, suffix := range divisionSuffixes {
+		if short, ok := strings.CutSuffix(name, suffix); ok && utf8.RuneCountInString(short) >= 2 {
+			return short
+		}
+	}
+	return name
+}
+
+// Lookup returns the divisions with the given full or short name.
+func (g *Gazetteer) Lookup(name string) []*Division {
+	return g.index[name]
+}
+
+// Contains reports whether name is the full or short name of a division.
+func (g *Gazetteer) Contains(name string) bool {
+	return len(g.index[name]) > 0
+}
+
+// match finds the longest division name of the given level at the start of text,
+// restricted to divisions within scope when scope is not nil.
+// It returns the division and the number of bytes consumed.
+func (g *Gazetteer) match(text string, level Level, scope *Division) (*Division, int) {
+	var ends []int
+	for i, r := range text {
+		ends = append(ends, i+utf8.RuneLen(r))
+		if len(ends) == g.maxLen {
+			break
+		}
+	}
+	for k := len(ends) - 1; k >= 0; k-- {
+		name, rest := text[:ends[k]], text[ends[k]:]
+		for _, d := range g.index[name] {
+			if d.Level != level || (scope != nil && !d.Within(scope)) {
+				continue
+			}
+			if name != d.Name && followsStreet(rest) {
+				// "中山路" is a road, not 中山市 + 路.
+				continue
+			}
+			n := len(name)
+			if r, size := utf8.DecodeRuneInString(rest); name != d.Name && size > 0 && IsDivisionSuffix(r) {
+				if !strings.HasSuffix(d.Name, string(r)) {
+					// "河北区" is a district of 天津, not 河北省 + 区.
+					continue
+				}
+				// "延边州": a short name followed by the last character of the full name.
+				n += size
+			}
+			return d, n
+		}
+	}
+	return nil, 0
+}
+
+// IsDivisionSuffix reports whether r ends administrative division names (省, 市, 区, 县, ...).
+func IsDivisionSuffix(r rune) bool {
+	return strings.ContainsRune("省市区县州盟旗乡镇", r)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/teatak/seg/crf"
+	"github.com/teatak/seg/dictionary"
+)
+
+func main() {
+	crfppPath := flag.String("crfpp", "", "Path to a CRF++ text model (crf_learn -t) to convert")
+	modelPath := flag.String("model", "data/model.crf", "Path to save the converted CRF model")
+	jiebaPath := flag.String("jieba", "", "Path to a jieba dict.txt (word freq tag) to convert")
+	dictPath := flag.String("dict", "data/dict_core.txt", "Path to save the converted dictionary layer")
+	flag.Parse()
+
+	if *crfppPath == "" && *jiebaPath == "" {
+		fmt.Println("Please provide -crfpp and/or -jieba")
+		os.Exit(1)
+	}
+
+	if *crfppPath != "" {
+		fmt.Printf("Importing CRF++ model %s...\n", *crfppPath)
+		model, err := crf.ImportCRFPP(*crfppPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+			os.Exit(1)
+		}
+		if err := model.Save(*modelPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Saving model failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved %d features to %s\n", len(model.Feats), *modelPath)
+	}
+
+	if *jiebaPath != "" {
+		fmt.Printf("Importing jieba dictionary %s...\n", *jiebaPath)
+		dict := dictionary.NewDictionary()
+		if err := dict.LoadJieba(*jiebaPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+			os.Exit(1)
+		}
+		if err := dict.Save(*dictPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Saving dictionary failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved %d words to %s\n", len(dict.Words), *dictPath)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/teatak/seg/fingerprint"
+	"github.com/teatak/seg/keywords"
+	"github.com/teatak/seg/optimizer"
+	"github.com/teatak/seg/util"
+)
+
+// runDedup implements "seg dedup": copy a corpus with one document per line, dropping the
+// lines that are near duplicates of an earlier one. Reads the file argument or stdin.
+//
+//	seg dedup data/text.txt > data/text.dedup.txt
+//	seg dedup -method minhash -threshold 0.8 -dups data/text.txt > /dev/null
+func runDedup(args []string) {
+	fs := flag.NewFlagSet("dedup", flag.ExitOnError)
+	method := fs.String("method", "simhash", "Fingerprint: simhash (weighted words) or minhash (word shingles)")
+	distance := fs.Int("distance", 3, "SimHash: largest Hamming distance of duplicates")
+	threshold := fs.Float64("threshold", 0.8, "MinHash: smallest estimated Jaccard similarity of duplicates")
+	hashes := fs.Int("hashes", 128, "MinHash: signature length")
+	bands := fs.Int("bands", 16, "MinHash: LSH bands, dividing -hashes; more bands compare more candidate pairs")
+	shingle := fs.Int("shingle", 2, "MinHash: shingle size in words")
+	idfPath := fs.String("idf", optimizer.IDFFile, "IDF table for word weights (dictionary frequency when missing)")
+	showDups := fs.Bool("dups", false, "Print each dropped line with the line it duplicates to stderr")
+	d := addDictFlags(fs)
+	fs.Parse(args)
+
+	var in io.Reader = os.Stdin
+	if fs.NArg() > 0 {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	seg := d.segmenter()
+	weight := fingerprint.FrequencyWeight(seg.Dict)
+	if util.FileExists(*idfPath) {
+		idf, err := keywords.LoadIDF(*idfPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading IDF table: %v\n", err)
+			os.Exit(1)
+		}
+		weight = idf.Get
+	}
+	fp := fingerprint.New(seg, weight)
+	fp.NumHashes = *hashes
+	fp.Shingle = *shingle
+
+	// find returns the index of the earlier line that the words duplicate, or adds them.
+	var find func(words []string) (int, bool)
+	switch *method {
+	case "simhash":
+		index := fingerprint.NewSimHashIndex(*distance)
+		find = func(words []string) (int, bool) {
+			h := fingerprint.SimHash(fp.WordFeatures(words))
+			if id, ok := index.Find(h); ok {
+				return id, true
+			}
+			index.Add(h)
+			return 0, false
+		}
+	case "minhash":
+		if err := fingerprint.ValidBands(*hashes, *bands); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v (-hashes must be a multiple of -bands)\n", err)
+			os.Exit(1)
+		}
+		index := fingerprint.NewMinHashIndex(*bands, *threshold)
+		find = func(words []string) (int, bool) {
+			sig := fingerprint.MinHash(fingerprint.Shingles(words, fp.Shingle), fp.NumHashes)
+			if id, ok := index.Find(sig); ok {
+				return id, true
+			}
+			index.Add(sig)
+			return 0, false
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown method '%s'. Use simhash or minhash.\n", *method)
+		os.Exit(1)
+	}
+
+	var kept []string // indexed lines, in the order of the index ids
+	total, dropped := 0, 0
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			fmt.Fprintln(out, line)
+			continue
+		}
+		total++
+		words := fp.Words(line)
+		if len(words) == 0 {
+			// Only punctuation or emoji: nothing to compare, keep the line.
+			fmt.Fprintln(out, line)
+			continue
+		}
+		if id, ok := find(words); ok {
+			dropped++
+			if *showDups {
+				fmt.Fprintf(os.Stderr, "- %s\n= %s\n", line, kept[id])
+			}
+			continue
+		}
+		kept = append(kept, line)
+		fmt.Fprintln(out, line)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Kept %d of %d lines (%d near duplicates dropped)\n", total-dropped, total, dropped)
+}
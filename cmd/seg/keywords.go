@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/keywords"
+	"github.com/teatak/seg/optimizer"
+	"github.com/teatak/seg/resources"
+	"github.com/teatak/seg/segmenter"
+	"github.com/teatak/seg/util"
+)
+
+// runKeywords implements "seg keywords": print the TF-IDF (or TextRank) keywords of the
+// arguments, or of stdin when there are none, or build the IDF table from raw text.
+//
+//	seg keywords -top 5 "希尔顿欢朋酒店北京市朝阳区"
+//	seg keywords -phrases "海景大床房，免费早餐，海景阳台"
+//	seg keywords -build data/text.txt
+func runKeywords(args []string) {
+	fs := flag.NewFlagSet("keywords", flag.ExitOnError)
+	top := fs.Int("top", 10, "Number of keywords to print (0 prints all)")
+	pos := fs.String("pos", "", "Comma separated dictionary tags to keep, e.g. n,nr,ns,brand")
+	idfPath := fs.String("idf", optimizer.IDFFile, "Path to the IDF table")
+	stopPath := fs.String("stop", "", "Path to extra stop words, one per line")
+	build := fs.String("build", "", "Compute the IDF table from this raw text file (one document per line) and exit")
+	withWeights := fs.Bool("weights", true, "Print the weight after each keyword")
+	textRank := fs.Bool("textrank", false, "Rank by TextRank over co-occurrence windows instead of TF-IDF (no IDF table needed)")
+	phrases := fs.Bool("phrases", false, "Print key phrases made of adjacent TextRank keywords instead of single words")
+	d := addDictFlags(fs)
+	fs.Parse(args)
+
+	if *build != "" {
+		// Cut the documents exactly like the extractor below.
+		ex := keywords.NewExtractor(d.segmenter(), nil)
+		if err := optimizer.ComputeIDF(ex.Seg, ex.Mode, *build, *idfPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error computing IDF: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("IDF table written to %s\n", *idfPath)
+		return
+	}
+
+	var idf *keywords.IDF
+	if util.FileExists(*idfPath) {
+		t, err := keywords.LoadIDF(*idfPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading IDF table: %v\n", err)
+			os.Exit(1)
+		}
+		idf = t
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: IDF table not found at %s. Ranking by term frequency.\n", *idfPath)
+	}
+
+	ex := keywords.NewExtractor(d.segmenter(), idf)
+	if *stopPath != "" {
+		if err := ex.LoadStopWords(*stopPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading stop words: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var tags []string
+	if *pos != "" {
+		tags = strings.Split(*pos, ",")
+	}
+
+	text := strings.Join(fs.Args(), " ")
+	if fs.NArg() == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+		text = string(data)
+	}
+	var result []keywords.Keyword
+	switch {
+	case *phrases:
+		result = ex.KeyPhrases(text, *top, tags...)
+	case *textRank:
+		result = ex.TextRank(text, *top, tags...)
+	default:
+		result = ex.Extract(text, *top, tags...)
+	}
+	for _, k := range result {
+		if *withWeights {
+			fmt.Printf("%s\t%.4f\n", k.Word, k.Weight)
+		} else {
+			fmt.Println(k.Word)
+		}
+	}
+}
+
+// dictFlags are the dictionary and model paths shared by the subcommands that segment text.
+type dictFlags struct {
+	core, base, user, model *string
+}
+
+func addDictFlags(fs *flag.FlagSet) dictFlags {
+	return dictFlags{
+		core:  fs.String("core", "data/dict_core.txt", "Path to core dictionary (embedded copy when missing)"),
+		base:  fs.String("base", "data/dict_base.txt", "Path to base dictionary (embedded copy when missing)"),
+		user:  fs.String("user", "data/dict_user.txt", "Path to user dictionary (embedded copy when missing)"),
+		model: fs.String("model", "data/model.crf", "Path to CRF model file (embedded copy when missing)"),
+	}
+}
+
+// dictionary loads the layers in order Core -> Base -> User, taking the embedded copy of
+// a missing file.
+func (d dictFlags) dictionary() *dictionary.Dictionary {
+	dict := dictionary.NewDictionary()
+	for _, layer := range []struct{ name, path string }{
+		{resources.CoreFile, *d.core},
+		{resources.BaseFile, *d.base},
+		{resources.UserFile, *d.user},
+	} {
+		if _, err := resources.LoadLayer(dict, layer.name, layer.path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading dictionary %s: %v\n", layer.path, err)
+			os.Exit(1)
+		}
+	}
+	return dict
+}
+
+// segmenter returns a segmenter over the dictionary and the CRF model, embedded when the
+// file is missing.
+func (d dictFlags) segmenter() *segmenter.Segmenter {
+	seg := segmenter.NewSegmenter(d.dictionary())
+	m, _, err := resources.LoadModel(*d.model)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading CRF model: %v\n", err)
+		os.Exit(1)
+	}
+	seg.CRFModel = m
+	return seg
+}
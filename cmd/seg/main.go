@@ -7,92 +7,187 @@ import (
 	"os"
 	"strings"
 
-	"github.com/teatak/seg/crf"
 	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/hmm"
+	"github.com/teatak/seg/resources"
 	"github.com/teatak/seg/segmenter"
 	"github.com/teatak/seg/util"
+	"github.com/teatak/seg/zhconv"
 )
 
 func main() {
-	function := flag.String("func", "cut", "Segmentation function: cut (standard) or search (for search engine)")
-	mode := flag.String("mode", "hybrid", "Algorithm mode: hybrid (recommended), dag, or crf")
-	basePath := flag.String("base", "data/dict_base.txt", "Path to base dictionary")
-	corePath := flag.String("core", "data/dict_core.txt", "Path to core dictionary")
-	userPath := flag.String("user", "data/dict_user.txt", "Path to user dictionary")
-	modelPath := flag.String("model", "data/model.crf", "Path to CRF model file")
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "model":
+			runModel(os.Args[2:])
+			return
+		case "prune":
+			runPrune(os.Args[2:])
+			return
+		case "split":
+			runSplit(os.Args[2:])
+			return
+		case "keywords":
+			runKeywords(os.Args[2:])
+			return
+		case "dedup":
+			runDedup(os.Args[2:])
+			return
+		}
+	}
+
+	function := flag.String("func", "cut", "Segmentation function: cut (standard), search (for search engine) or expand (search plus synonyms)")
+	mode := flag.String("mode", "hybrid", "Algorithm: "+strings.Join(segmenter.TokenizerNames(), ", ")+" (hybrid recommended)")
+	strategyName := flag.String("strategy", "length", "Hybrid strategy: length (trust multi-character words), frequency, marginal (CRF veto) or joint (joint lattice)")
+	basePath := flag.String("base", "data/dict_base.txt", "Path to base dictionary (embedded copy when missing)")
+	corePath := flag.String("core", "data/dict_core.txt", "Path to core dictionary (embedded copy when missing)")
+	userPath := flag.String("user", "data/dict_user.txt", "Path to user dictionary (embedded copy when missing)")
+	modelPath := flag.String("model", "data/model.crf", "Path to CRF model file (embedded copy when missing)")
+	corpusPath := flag.String("corpus", "data/corpus.txt", "Training corpus (space separated words) for the HMM of -mode hmm")
+	patternsPath := flag.String("patterns", "data/patterns.txt", "Path to extra pre-tokenizer patterns (TYPE regexp per line)")
+	showTypes := flag.Bool("types", false, "Append the type of atomic tokens (URL, EMAIL, DATE, ...) as word/TYPE")
+	normalize := flag.Bool("normalize", true, "Fold full-width and compatibility characters before segmentation")
+	lower := flag.Bool("lower", false, "Also fold case before segmentation (tokens keep the original text)")
+	traditional := flag.Bool("t2s", false, "Segment traditional Chinese through the simplified dictionary (tokens keep the original script)")
+	filterNames := flag.String("filters", "", "Comma separated token filters applied in order: punct, stopwords, lowercase, synonyms, pinyin")
+	stopPath := flag.String("stopwords", "data/stopwords.txt", "Extra stop words for the stopwords filter, one per line")
+	synonymPath := flag.String("synonyms", "data/dict_synonyms.txt", "Synonym layer (one group of aliases per line) for -func expand and the synonyms filter")
+	minLen := flag.Int("min-len", 0, "Drop tokens shorter than this many characters")
+	maxLen := flag.Int("max-len", 0, "Drop tokens longer than this many characters (0 = no limit)")
+	withPinyin := flag.Bool("pinyin", false, "Add the full pinyin and the initials after each Chinese word (e.g. 如家 / rujia / rj)")
 	flag.Parse()
 
-	// 1. Resolve Mode Constant
-	var segMode segmenter.Mode
-	switch *mode {
-	case "hybrid":
-		segMode = segmenter.ModeHybrid
-	case "crf":
-		segMode = segmenter.ModeCRF
-	case "dag":
-		segMode = segmenter.ModeDAG
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown mode '%s'. Using 'hybrid'.\n", *mode)
-		segMode = segmenter.ModeHybrid
-		*mode = "hybrid" // Normalize for loading logic
+	// 1. Resolve the algorithm
+	tokenizer, err := segmenter.TokenizerByName(*mode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	// 2. Load Resources (Dict / Model) based on Algorithm Mode
-
 	// 2. Load Resources (Dict / Model)
 	dict := dictionary.NewDictionary()
 
 	// Load hierarchical dictionaries in order: Core -> Base -> User
-	// (Last one loaded wins frequency and existence)
-	if util.FileExists(*corePath) {
-		dict.Load(*corePath)
-	}
-	if util.FileExists(*basePath) {
-		dict.Load(*basePath)
+	// (Last one loaded wins frequency and existence; a missing file falls back to the embedded layer)
+	for _, layer := range []struct{ name, path string }{
+		{resources.CoreFile, *corePath},
+		{resources.BaseFile, *basePath},
+		{resources.UserFile, *userPath},
+	} {
+		if _, err := resources.LoadLayer(dict, layer.name, layer.path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading dictionary %s: %v\n", layer.path, err)
+			os.Exit(1)
+		}
 	}
-	if util.FileExists(*userPath) {
-		dict.Load(*userPath)
+	if util.FileExists(*synonymPath) {
+		if err := dict.LoadSynonyms(*synonymPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading synonyms: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	if dict.Total == 0 && *mode != "crf" {
-		fmt.Fprintf(os.Stderr, "Warning: No dictionary loaded. Standard mode may be inaccurate.\n")
+	seg := segmenter.NewSegmenter(dict)
+	seg.Tokenizer = tokenizer
+	if !*normalize {
+		seg.Normalizer = nil
+	} else {
+		seg.Normalizer.Lowercase = *lower
+	}
+	if *traditional {
+		seg.Converter = zhconv.Default()
+	}
+	strategy, err := segmenter.NewStrategy(*strategyName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	seg.Strategy = strategy
+	if util.FileExists(*patternsPath) {
+		if err := seg.PreTokenizer.LoadPatterns(*patternsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading patterns: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	seg := segmenter.NewSegmenter(dict)
+	if *filterNames != "" || *minLen > 0 || *maxLen > 0 || *withPinyin {
+		registry := segmenter.NewFilterRegistry(dict)
+		if util.FileExists(*stopPath) {
+			stop := segmenter.DefaultStopWords().Clone()
+			if err := stop.Load(*stopPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Error loading stop words: %v\n", err)
+				os.Exit(1)
+			}
+			registry["stopwords"] = stop
+		}
+		var names []string
+		if *filterNames != "" {
+			names = strings.Split(*filterNames, ",")
+		}
+		chain, err := registry.Chain(names...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *minLen > 0 || *maxLen > 0 {
+			chain = append(chain, segmenter.LengthFilter(*minLen, *maxLen))
+		}
+		if *withPinyin {
+			chain = append(chain, segmenter.NewPinyinFilter())
+		}
+		seg.Filters = chain
+	}
 
-	// Load CRF Model
+	// Load CRF Model (the embedded one when the file is missing)
 	// Required for: crf
 	// Recommended for: hybrid
-	if *mode == "crf" || *mode == "hybrid" || util.FileExists(*modelPath) {
-		if util.FileExists(*modelPath) {
-			crfModel := crf.NewModel()
-			if err := crfModel.Load(*modelPath); err != nil {
-				fmt.Fprintf(os.Stderr, "Error loading CRF model: %v\n", err)
-				if *mode == "crf" {
-					os.Exit(1)
-				}
-			} else {
-				seg.CRFModel = crfModel
-			}
-		} else {
-			if *mode == "crf" {
-				fmt.Fprintf(os.Stderr, "Error: CRF model file not found at %s. Required for mode 'crf'.\n", *modelPath)
-				os.Exit(1)
-			}
-			if *mode == "hybrid" {
-				fmt.Fprintf(os.Stderr, "Warning: CRF model not found at %s. Downgrading 'hybrid' to DAG-only.\n", *modelPath)
-			}
+	crfModel, _, err := resources.LoadModel(*modelPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading CRF model: %v\n", err)
+		if *mode == "crf" {
+			os.Exit(1)
 		}
+	} else {
+		seg.CRFModel = crfModel
+	}
+
+	// Train the HMM
+	// Required for: hmm
+	if *mode == "hmm" {
+		if !util.FileExists(*corpusPath) {
+			fmt.Fprintf(os.Stderr, "Error: corpus file not found at %s. Required for mode 'hmm'.\n", *corpusPath)
+			os.Exit(1)
+		}
+		hmmModel, err := hmm.TrainFile(*corpusPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error training HMM: %v\n", err)
+			os.Exit(1)
+		}
+		seg.HMMModel = hmmModel
 	}
 
 	// Helper to process text
 	process := func(text string) []string {
 		// Dispatch based on Function
 		if *function == "search" {
-			return seg.CutSearch(text, segMode)
+			return seg.CutSearch(text)
+		}
+		if *function == "expand" {
+			return seg.CutSearchExpand(text)
+		}
+		if *showTypes {
+			var res []string
+			for _, t := range seg.Tokens(text) {
+				if t.Type != "" {
+					res = append(res, t.Text+"/"+t.Type)
+				} else {
+					res = append(res, t.Text)
+				}
+			}
+			return res
 		}
 		// Default to cut
-		return seg.Cut(text, segMode)
+		return seg.Cut(text)
 	}
 
 	// If args provided (non-flag args), segment them
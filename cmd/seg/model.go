@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/teatak/seg/crf"
+)
+
+// runModel implements "seg model": inspect a CRF model or diff two models.
+//
+//	seg model [-top 10] [-bins 10] data/model.crf
+//	seg model -diff old.crf new.crf
+func runModel(args []string) {
+	fs := flag.NewFlagSet("model", flag.ExitOnError)
+	top := fs.Int("top", 10, "Number of top features (or largest changes) to show")
+	bins := fs.Int("bins", 10, "Number of weight histogram bins")
+	diff := fs.Bool("diff", false, "Compare two models: seg model -diff old.crf new.crf")
+	fs.Parse(args)
+
+	paths := fs.Args()
+	if *diff {
+		if len(paths) != 2 {
+			fmt.Fprintln(os.Stderr, "Usage: seg model -diff old.crf new.crf")
+			os.Exit(1)
+		}
+		diffModels(loadModel(paths[0]), loadModel(paths[1]), *top)
+		return
+	}
+
+	path := "data/model.crf"
+	if len(paths) > 0 {
+		path = paths[0]
+	}
+	fmt.Printf("Model: %s\n", path)
+	inspectModel(loadModel(path), *top, *bins)
+}
+
+func loadModel(path string) *crf.Model {
+	m := crf.NewModel()
+	if err := m.Load(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading CRF model: %v\n", err)
+		os.Exit(1)
+	}
+	return m
+}
+
+func inspectModel(m *crf.Model, top, bins int) {
+	s := m.Summarize()
+	fmt.Printf("Features: %d  Weights: %d  Range: [%.2f, %.2f]\n", s.Features, s.Weights, s.Min, s.Max)
+
+	var tmpls []string
+	for t := range s.Templates {
+		tmpls = append(tmpls, t)
+	}
+	sort.Strings(tmpls)
+	fmt.Println("\nFeatures per template:")
+	for _, t := range tmpls {
+		fmt.Printf("  %-6s %d\n", t, s.Templates[t])
+	}
+	fmt.Println("\nWeights per tag:")
+	for tag := 0; tag < m.NumTags(); tag++ {
+		fmt.Printf("  %-6s %d\n", m.TagName(tag), s.TagWeights[tag])
+	}
+
+	fmt.Println("\nTransitions (row = from, column = to):")
+	printTransitions(m.Labels, m.Trans, m.Start, m.End)
+
+	fmt.Println("\nTop features per tag:")
+	for tag := 0; tag < m.NumTags(); tag++ {
+		var parts []string
+		for _, fw := range m.TopFeatures(tag, top) {
+			parts = append(parts, fmt.Sprintf("%s(%.1f)", fw.Feature, fw.Weight))
+		}
+		fmt.Printf("  %s: %s\n", m.TagName(tag), strings.Join(parts, " "))
+	}
+
+	edges, counts := m.Histogram(bins)
+	if len(edges) == 0 {
+		return
+	}
+	fmt.Println("\nWeight histogram:")
+	maxCount := 0
+	for _, c := range counts {
+		maxCount = max(maxCount, c)
+	}
+	width := 0.0
+	if len(edges) > 1 {
+		width = edges[1] - edges[0]
+	}
+	for i, c := range counts {
+		bar := strings.Repeat("#", int(math.Ceil(float64(c)/float64(maxCount)*40)))
+		fmt.Printf("  [%8.2f, %8.2f) %8d %s\n", edges[i], edges[i]+width, c, bar)
+	}
+}
+
+func diffModels(from, to *crf.Model, top int) {
+	d := crf.DiffModels(from, to)
+	fmt.Printf("Features added: %d  removed: %d  weights changed: %d\n", len(d.Added), len(d.Removed), len(d.Changed))
+
+	if d.Trans != nil {
+		fmt.Println("\nTransition changes (new - old):")
+		printTransitions(to.Labels, d.Trans, d.Start, d.End)
+	}
+
+	fmt.Printf("\nLargest weight changes:\n")
+	for i, c := range d.Changed {
+		if i >= top {
+			break
+		}
+		fmt.Printf("  %-20s %s %8.2f -> %8.2f (%+.2f)\n", c.Feature, to.TagName(c.Tag), c.Old, c.New, c.Delta())
+	}
+
+	printList := func(title string, feats []string) {
+		if len(feats) == 0 {
+			return
+		}
+		fmt.Printf("\n%s (showing %d of %d):\n", title, min(top, len(feats)), len(feats))
+		for i, f := range feats {
+			if i >= top {
+				break
+			}
+			fmt.Printf("  %s\n", f)
+		}
+	}
+	printList("Features added", d.Added)
+	printList("Features removed", d.Removed)
+}
+
+func printTransitions(labels []string, trans [][]float64, start, end []float64) {
+	fmt.Printf("  %-8s", "")
+	for _, l := range labels {
+		fmt.Printf("%9s", l)
+	}
+	fmt.Printf("%9s\n", crf.TagEOS)
+	fmt.Printf("  %-8s", crf.TagBOS)
+	for to := range labels {
+		fmt.Printf("%9.2f", start[to])
+	}
+	fmt.Println()
+	for from, l := range labels {
+		fmt.Printf("  %-8s", l)
+		for to := range labels {
+			fmt.Printf("%9.2f", trans[from][to])
+		}
+		fmt.Printf("%9.2f\n", end[from])
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/teatak/seg/crf"
+)
+
+// runPrune implements "seg prune": shrink a CRF model by pruning and quantization,
+// optionally reporting the size vs. accuracy trade-off on an evaluation corpus.
+//
+//	seg prune -eval data/corpus.txt data/model.crf
+//	seg prune -min-weight 2 -bits 8 -output data/model.small.crf data/model.crf
+func runPrune(args []string) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	minWeight := fs.Float64("min-weight", 0, "Drop feature weights with absolute value below this")
+	minFreq := fs.Int("min-freq", 0, "Drop features seen fewer times than this in -freq-corpus")
+	freqCorpus := fs.String("freq-corpus", "data/corpus.txt", "Segmented corpus used to count feature frequency")
+	bits := fs.Int("bits", 0, "Quantize feature weights to 8 or 16 bits (0 keeps full precision)")
+	evalPath := fs.String("eval", "", "Segmented corpus to report size vs. accuracy on")
+	sweep := fs.String("sweep", "1,2,4,8", "Comma separated -min-weight values compared in the -eval report")
+	output := fs.String("output", "", "Path to save the pruned model")
+	fs.Parse(args)
+
+	path := "data/model.crf"
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+	original := loadModel(path)
+
+	var counts map[string]int
+	if *minFreq > 0 {
+		sents, err := crf.LoadCorpus(*freqCorpus)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading frequency corpus: %v\n", err)
+			os.Exit(1)
+		}
+		counts = crf.FeatureCounts(sents)
+	}
+
+	compress := func(minAbs float64) *crf.Model {
+		m := original.Clone()
+		if minAbs > 0 {
+			m.PruneWeights(minAbs)
+		}
+		if *minFreq > 0 {
+			m.PruneFeatures(counts, *minFreq)
+		}
+		if *bits > 0 {
+			if err := m.Quantize(*bits); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		return m
+	}
+
+	pruned := compress(*minWeight)
+
+	if *evalPath != "" {
+		sents, err := crf.LoadCorpus(*evalPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading eval corpus: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Eval: %s (%d sentences), min-freq=%d, bits=%d\n\n", *evalPath, len(sents), *minFreq, *bits)
+		fmt.Printf("%-16s %9s %9s %10s %8s %8s\n", "Variant", "Features", "Weights", "Bytes", "TagAcc", "F1")
+		report := func(name string, m *crf.Model) {
+			s := m.Summarize()
+			r := m.Evaluate(sents)
+			fmt.Printf("%-16s %9d %9d %10d %7.2f%% %7.2f%%\n", name, s.Features, s.Weights, modelSize(m), r.TagAccuracy*100, r.F1*100)
+		}
+		report("original", original)
+		report(fmt.Sprintf("min-weight=%g", *minWeight), pruned)
+		for _, v := range strings.Split(*sweep, ",") {
+			minAbs, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil || minAbs == *minWeight {
+				continue
+			}
+			report(fmt.Sprintf("min-weight=%g", minAbs), compress(minAbs))
+		}
+	}
+
+	if *output != "" {
+		if err := pruned.Save(*output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving model: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved pruned model (%d bytes) to %s\n", modelSize(pruned), *output)
+	}
+}
+
+// modelSize returns the size of the model in its saved text format.
+func modelSize(m *crf.Model) int64 {
+	var c byteCounter
+	m.Write(&c)
+	return int64(c)
+}
+
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
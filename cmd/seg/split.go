@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/teatak/seg/util"
+)
+
+// runSplit implements "seg split": print the sentences of the arguments, or of stdin
+// when there are none, one per line.
+//
+//	seg split "今天天气很好。我们去公园吧！"
+//	seg split -offsets < article.txt
+func runSplit(args []string) {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	offsets := fs.Bool("offsets", false, "Prefix each sentence with its rune offsets as start<TAB>end<TAB>")
+	fs.Parse(args)
+
+	text := strings.Join(fs.Args(), " ")
+	if fs.NArg() == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+		text = string(data)
+	}
+
+	for _, s := range util.SplitSentences(text) {
+		if *offsets {
+			fmt.Printf("%d\t%d\t%s\n", s.Start, s.End, s.Text)
+		} else {
+			fmt.Println(s.Text)
+		}
+	}
+}
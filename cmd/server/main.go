@@ -2,34 +2,67 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/teatak/seg/address"
 	"github.com/teatak/seg/crf"
 	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/hmm"
+	"github.com/teatak/seg/hotel"
+	"github.com/teatak/seg/keywords"
+	"github.com/teatak/seg/ner"
 	"github.com/teatak/seg/optimizer"
+	"github.com/teatak/seg/resources"
 	"github.com/teatak/seg/segmenter"
 	"github.com/teatak/seg/util"
+	"github.com/teatak/seg/zhconv"
 )
 
 // Global segmenter with RWMutex for hot reloading
 var (
 	seg     *segmenter.Segmenter
+	hotels  *hotel.Parser
+	idf     *keywords.IDF
+	filters segmenter.FilterRegistry
 	segLock sync.RWMutex
+	// trainLock serializes online updates and full optimization runs,
+	// which all write data/model.crf.
+	trainLock sync.Mutex
+	// hmmModel is trained from the corpus on first use, see trainedHMM.
+	hmmModel *hmm.Model
+	hmmLock  sync.Mutex
+	// replayPool holds the corpus replayed by online updates, loaded once by replayCorpus;
+	// replayRand draws from it. Both are used under trainLock.
+	replayPool []crf.Sentence
+	replayRand *rand.Rand
 )
 
 const (
 	DataDir      = "data"
 	LogFile      = "data/server_access.log"    // 沉淀用户输入
 	NewWordsFile = "data/server_new_words.txt" // 挖掘出的新词
+	NERModelFile = "data/ner.crf"              // 实体识别模型 (可选)
+	PatternsFile = "data/patterns.txt"         // 自定义整词模式 (可选)
+	StopWordFile = "data/stopwords.txt"        // 追加停用词 (可选)
+	SynonymFile  = "data/dict_synonyms.txt"    // 同义词/别名层 (可选)
 )
 
 func main() {
+	retrain := flag.Duration("retrain", 0, "Run the full optimization pipeline periodically (e.g. 1h); 0 disables")
+	seed := flag.Int64("seed", 1, "Seed of the corpus sample replayed by online updates")
+	flag.Parse()
+	replayRand = rand.New(rand.NewSource(*seed))
+
 	// 1. Initial Load
 	if err := reloadEngine(); err != nil {
 		log.Fatalf("Initial load failed: %v", err)
@@ -47,9 +80,24 @@ func main() {
 	http.HandleFunc("/segment", func(w http.ResponseWriter, r *http.Request) {
 		handleSegment(w, r, logF)
 	})
+	http.HandleFunc("/entities", handleEntities)         // 实体识别
+	http.HandleFunc("/hotel", handleHotel)               // 酒店名称结构化
+	http.HandleFunc("/address", handleAddress)           // 地址解析
+	http.HandleFunc("/keywords", handleKeywords)         // 关键词抽取
+	http.HandleFunc("/explain", handleExplain)           // 分词过程解释 (词图、来源、CRF 打分)
 	http.HandleFunc("/feedback", handleFeedback)         // 人工教词
 	http.HandleFunc("/trigger-discovery", handleTrigger) // 触发自动挖掘 & 训练
 
+	if *retrain > 0 {
+		go func() {
+			for range time.Tick(*retrain) {
+				if info, err := os.Stat(NewWordsFile); err == nil && info.Size() > 0 {
+					runOptimization()
+				}
+			}
+		}()
+	}
+
 	log.Println("Server started on :8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
@@ -60,42 +108,106 @@ func reloadEngine() error {
 	dict := dictionary.NewDictionary()
 
 	// Load hierarchical dictionaries in order: Core -> Base -> User
-	// (Last one loaded wins frequency and existence)
+	// (Last one loaded wins frequency and existence; a missing file falls back to the embedded layer)
 	paths := []struct {
 		name string
-		path string
+		file string
 	}{
-		{"Core", "data/dict_core.txt"},
-		{"Base", "data/dict_base.txt"},
-		{"User", "data/dict_user.txt"},
+		{"Core", resources.CoreFile},
+		{"Base", resources.BaseFile},
+		{"User", resources.UserFile},
 	}
 
 	for _, d := range paths {
-		if util.FileExists(d.path) {
-			if err := dict.Load(d.path); err != nil {
-				log.Printf("Error loading %s dictionary: %v", d.name, err)
-			} else {
-				log.Printf("Loaded %s dictionary.", d.name)
-			}
+		embedded, err := resources.LoadLayer(dict, d.file, filepath.Join(DataDir, d.file))
+		switch {
+		case err != nil:
+			log.Printf("Error loading %s dictionary: %v", d.name, err)
+		case embedded:
+			log.Printf("Note: %s dictionary not found, using the embedded one.", d.name)
+		default:
+			log.Printf("Loaded %s dictionary.", d.name)
+		}
+	}
+
+	if util.FileExists(SynonymFile) {
+		if err := dict.LoadSynonyms(SynonymFile); err != nil {
+			log.Printf("Error loading synonyms: %v", err)
 		} else {
-			log.Printf("Note: %s dictionary not found.", d.name)
+			log.Println("Loaded synonym layer.")
 		}
 	}
 
 	newSeg := segmenter.NewSegmenter(dict)
-	model := crf.NewModel()
-	if util.FileExists("data/model.crf") {
-		if err := model.Load("data/model.crf"); err == nil {
-			newSeg.CRFModel = model
+	newSeg.Tokenizer = segmenter.HybridTokenizer{}
+	if util.FileExists(PatternsFile) {
+		if err := newSeg.PreTokenizer.LoadPatterns(PatternsFile); err != nil {
+			log.Printf("Error loading patterns: %v", err)
+		}
+	}
+	if model, embedded, err := resources.LoadModel(filepath.Join(DataDir, resources.ModelFile)); err != nil {
+		log.Printf("Error loading CRF model: %v, running in pure DAG mode.", err)
+	} else {
+		if embedded {
+			log.Println("Note: No CRF model found, using the embedded one.")
+		}
+		newSeg.CRFModel = model
+	}
+
+	// The HMM serves the "hmm" algorithm and hybrid without a CRF model; with a CRF model
+	// it is trained on the first "hmm" request instead.
+	hmmLock.Lock()
+	hmmModel = nil
+	hmmLock.Unlock()
+	if newSeg.CRFModel == nil {
+		if model, err := trainedHMM(); err != nil {
+			log.Printf("Error training HMM: %v", err)
+		} else {
+			newSeg.HMMModel = model
+		}
+	}
+
+	if util.FileExists(NERModelFile) {
+		nerModel := crf.NewModel()
+		if err := nerModel.Load(NERModelFile); err == nil {
+			newSeg.NERModel = nerModel
+		} else {
+			log.Printf("Error loading NER model: %v", err)
+		}
+	}
+
+	brands, err := optimizer.LoadTopBrands(optimizer.DictBase)
+	if err != nil {
+		log.Printf("Note: brand list not loaded: %v", err)
+	}
+	newHotels := hotel.NewParser(newSeg, brands...)
+
+	newIDF := keywords.NewIDF()
+	if util.FileExists(optimizer.IDFFile) {
+		if t, err := keywords.LoadIDF(optimizer.IDFFile); err == nil {
+			newIDF = t
 		} else {
-			log.Printf("Error loading CRF model: %v", err)
+			log.Printf("Error loading IDF table: %v", err)
 		}
 	} else {
-		log.Println("Warning: No CRF model found, running in pure DAG mode.")
+		log.Println("Note: IDF table not found, keywords are ranked by term frequency.")
+	}
+
+	newFilters := segmenter.NewFilterRegistry(dict)
+	if util.FileExists(StopWordFile) {
+		stop := segmenter.DefaultStopWords().Clone()
+		if err := stop.Load(StopWordFile); err == nil {
+			newFilters["stopwords"] = stop
+		} else {
+			log.Printf("Error loading stop words: %v", err)
+		}
 	}
 
 	segLock.Lock()
 	seg = newSeg
+	hotels = newHotels
+	idf = newIDF
+	filters = newFilters
 	segLock.Unlock()
 	log.Println("Engine reloaded successfully.")
 	return nil
@@ -104,12 +216,23 @@ func reloadEngine() error {
 // Request/Response types
 type SegRequest struct {
 	Text      string `json:"text"`
-	Function  string `json:"function"`  // standard, search
-	Algorithm string `json:"algorithm"` // hybrid, crf, dag
+	Function  string `json:"function"`  // standard, search, expand (search plus synonyms)
+	Algorithm string `json:"algorithm"` // hybrid (default), crf, dag, hmm or a registered tokenizer
+	Strategy  string `json:"strategy"`  // hybrid strategy: length (default), frequency, marginal, joint
+	Details   bool   `json:"details"`   // also return offsets and types of the tokens
+	// Traditional segments traditional Chinese through the simplified dictionary.
+	Traditional bool `json:"traditional"`
+	// Filters post-process the tokens in order: punct, stopwords, lowercase, synonyms, pinyin.
+	Filters []string `json:"filters"`
+	// Pinyin adds the full pinyin and the initials after each Chinese word, for search indexes.
+	Pinyin bool `json:"pinyin"`
+	MinLen int  `json:"min_len"` // drop tokens shorter than this (runes)
+	MaxLen int  `json:"max_len"` // drop tokens longer than this (runes); 0 = no limit
 }
 
 type SegResponse struct {
-	Tokens []string `json:"tokens"`
+	Tokens  []string          `json:"tokens"`
+	Details []segmenter.Token `json:"details,omitempty"`
 }
 
 func handleSegment(w http.ResponseWriter, r *http.Request, logFile io.Writer) {
@@ -135,27 +258,240 @@ func handleSegment(w http.ResponseWriter, r *http.Request, logFile io.Writer) {
 	// 2. Process
 	segLock.RLock()
 	s := seg
+	registry := filters
 	segLock.RUnlock()
 
-	// Resolve Algorithm Mode
-	var segMode segmenter.Mode
-	switch req.Algorithm {
-	case "crf":
-		segMode = segmenter.ModeCRF
-	case "dag":
-		segMode = segmenter.ModeDAG
+	chain, err := registry.Chain(req.Filters...)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if req.MinLen > 0 || req.MaxLen > 0 {
+		chain = append(chain, segmenter.LengthFilter(req.MinLen, req.MaxLen))
+	}
+	if req.Pinyin {
+		chain = append(chain, registry["pinyin"])
+	}
+	s, err = requestSegmenter(s, req)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if len(chain) > 0 {
+		sc := *s
+		sc.Filters = chain
+		s = &sc
+	}
+
+	var resp SegResponse
+	switch {
+	case req.Function == "search":
+		resp.Tokens = s.CutSearch(req.Text)
+	case req.Function == "expand" && req.Details:
+		resp.Details = s.ExpandTokens(req.Text)
+		for _, t := range resp.Details {
+			resp.Tokens = append(resp.Tokens, t.Text)
+		}
+	case req.Function == "expand":
+		resp.Tokens = s.CutSearchExpand(req.Text)
+	case req.Details:
+		resp.Details = s.Tokens(req.Text)
+		for _, t := range resp.Details {
+			resp.Tokens = append(resp.Tokens, t.Text)
+		}
 	default:
-		segMode = segmenter.ModeHybrid
+		resp.Tokens = s.Cut(req.Text)
 	}
 
-	var tokens []string
-	if req.Function == "search" {
-		tokens = s.CutSearch(req.Text, segMode)
-	} else {
-		tokens = s.Cut(req.Text, segMode)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// requestSegmenter returns a copy of s with the algorithm, the script conversion and the
+// hybrid strategy of the request, or s itself when the request uses the defaults.
+func requestSegmenter(s *segmenter.Segmenter, req SegRequest) (*segmenter.Segmenter, error) {
+	if req.Algorithm == "" && !req.Traditional && req.Strategy == "" {
+		return s, nil
 	}
+	sc := *s
+	if req.Algorithm != "" {
+		tokenizer, err := segmenter.TokenizerByName(req.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		sc.Tokenizer = tokenizer
+		if _, ok := tokenizer.(segmenter.HMMTokenizer); ok && sc.HMMModel == nil {
+			if sc.HMMModel, err = trainedHMM(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if req.Traditional {
+		sc.Converter = zhconv.Default()
+	}
+	if req.Strategy != "" {
+		strategy, err := segmenter.NewStrategy(req.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		sc.Strategy = strategy
+	}
+	return &sc, nil
+}
+
+// trainedHMM returns the HMM trained from the corpus, training it on first use after a
+// reload; it is nil when there is no corpus, and the hmm algorithm then falls back to the DAG.
+func trainedHMM() (*hmm.Model, error) {
+	hmmLock.Lock()
+	defer hmmLock.Unlock()
+	if hmmModel == nil && util.FileExists(optimizer.CorpusFile) {
+		model, err := hmm.TrainFile(optimizer.CorpusFile)
+		if err != nil {
+			return nil, err
+		}
+		hmmModel = model
+	}
+	return hmmModel, nil
+}
+
+type ExplainResponse struct {
+	Explanation *segmenter.Explanation `json:"explanation"`
+}
+
+func handleExplain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", 405)
+		return
+	}
+
+	var req SegRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	segLock.RLock()
+	s := seg
+	segLock.RUnlock()
+
+	s, err := requestSegmenter(s, req)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	json.NewEncoder(w).Encode(ExplainResponse{Explanation: s.Explain(req.Text)})
+}
 
-	json.NewEncoder(w).Encode(SegResponse{Tokens: tokens})
+type EntityResponse struct {
+	Entities []ner.Entity `json:"entities"`
+}
+
+func handleEntities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", 405)
+		return
+	}
+
+	var req SegRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	segLock.RLock()
+	s := seg
+	segLock.RUnlock()
+
+	entities := s.Entities(req.Text)
+	if entities == nil {
+		entities = []ner.Entity{}
+	}
+	json.NewEncoder(w).Encode(EntityResponse{Entities: entities})
+}
+
+type HotelResponse struct {
+	Listing hotel.Listing `json:"listing"`
+}
+
+func handleHotel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", 405)
+		return
+	}
+
+	var req SegRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	segLock.RLock()
+	p := hotels
+	segLock.RUnlock()
+
+	json.NewEncoder(w).Encode(HotelResponse{Listing: p.Parse(req.Text)})
+}
+
+type AddressResponse struct {
+	Address address.Address `json:"address"`
+}
+
+func handleAddress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", 405)
+		return
+	}
+
+	var req SegRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	json.NewEncoder(w).Encode(AddressResponse{Address: address.Parse(req.Text)})
+}
+
+type KeywordsRequest struct {
+	Text      string   `json:"text"`
+	TopK      int      `json:"top_k"`     // default 10
+	POS       []string `json:"pos"`       // keep only dictionary words with these tags, e.g. ["n", "ns"]
+	Algorithm string   `json:"algorithm"` // tfidf (default), textrank
+	Phrases   bool     `json:"phrases"`   // also return key phrases of adjacent TextRank keywords
+}
+
+type KeywordsResponse struct {
+	Keywords []keywords.Keyword `json:"keywords"`
+	Phrases  []keywords.Keyword `json:"phrases,omitempty"`
+}
+
+func handleKeywords(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", 405)
+		return
+	}
+
+	var req KeywordsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if req.TopK <= 0 {
+		req.TopK = 10
+	}
+
+	segLock.RLock()
+	ex := keywords.NewExtractor(seg, idf)
+	segLock.RUnlock()
+
+	var resp KeywordsResponse
+	if req.Algorithm == "textrank" {
+		resp.Keywords = ex.TextRank(req.Text, req.TopK, req.POS...)
+	} else {
+		resp.Keywords = ex.Extract(req.Text, req.TopK, req.POS...)
+	}
+	if req.Phrases {
+		resp.Phrases = ex.KeyPhrases(req.Text, req.TopK, req.POS...)
+	}
+	json.NewEncoder(w).Encode(resp)
 }
 
 func handleFeedback(w http.ResponseWriter, r *http.Request) {
@@ -187,15 +523,72 @@ func handleFeedback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Trigger optimize immediately? Or let user trigger separately.
-	// Let's trigger immediately for "instant feedback" feel.
-	go runOptimization()
+	// Apply the correction to the CRF model right away for "instant feedback" feel.
+	// The words stay in NewWordsFile until the next full optimization merges them,
+	// which runs on /trigger-discovery, periodically (-retrain) or with full=1.
+	go runOnlineUpdate(words)
 
 	action := "added"
 	if len(words) > 1 {
 		action = "split and added"
 	}
-	fmt.Fprintf(w, "Words %v. Optimization started in background.", action)
+	if r.URL.Query().Get("full") == "1" {
+		go runOptimization()
+		fmt.Fprintf(w, "Words %v. Optimization started in background.", action)
+		return
+	}
+	fmt.Fprintf(w, "Words %v. Model updated online.", action)
+}
+
+// runOnlineUpdate applies a single correction to the live CRF model and saves it.
+func runOnlineUpdate(words []string) {
+	trainLock.Lock()
+	defer trainLock.Unlock()
+
+	segLock.RLock()
+	s := seg
+	segLock.RUnlock()
+	if s.CRFModel == nil {
+		return
+	}
+
+	sent := crf.SentenceFromWords(words)
+	if len(sent.Runes) == 0 {
+		return
+	}
+
+	start := time.Now()
+	pool, err := replayCorpus()
+	if err != nil {
+		log.Printf("Online update failed: %v", err)
+		return
+	}
+	model := optimizer.OnlineUpdate(s.CRFModel, []crf.Sentence{sent}, pool, optimizer.OnlineReplaySize, replayRand)
+	if err := model.Save(optimizer.ModelFile); err != nil {
+		log.Printf("Saving model failed: %v", err)
+		return
+	}
+
+	// Keep the dictionary, NER model and pre-tokenizer of the live segmenter.
+	newSeg := *s
+	newSeg.CRFModel = model
+	segLock.Lock()
+	seg = &newSeg
+	segLock.Unlock()
+	log.Printf("Online update applied in %v.", time.Since(start))
+}
+
+// replayCorpus loads the corpus for online updates on first use; the caller holds trainLock.
+// A full optimization rewrites the corpus and clears the pool.
+func replayCorpus() ([]crf.Sentence, error) {
+	if replayPool == nil && util.FileExists(optimizer.CorpusFile) {
+		corpus, err := crf.LoadCorpus(optimizer.CorpusFile)
+		if err != nil {
+			return nil, err
+		}
+		replayPool = corpus
+	}
+	return replayPool, nil
 }
 
 func handleTrigger(w http.ResponseWriter, r *http.Request) {
@@ -240,6 +633,9 @@ func handleTrigger(w http.ResponseWriter, r *http.Request) {
 }
 
 func runOptimization() {
+	trainLock.Lock()
+	defer trainLock.Unlock()
+
 	log.Println("Starting optimization pipeline...")
 
 	// Call internal optimizer pipeline directly
@@ -248,6 +644,7 @@ func runOptimization() {
 		return
 	}
 	log.Printf("Optimization finished.")
+	replayPool = nil // the corpus was re-segmented
 
 	// Clear the new words file so we don't re-add them next time?
 	// Actually optimizer.Run merges them into main dict.
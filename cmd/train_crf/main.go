@@ -11,8 +11,11 @@ import (
 func main() {
 	inputPath := flag.String("input", "data/corpus.txt", "Path to the segmented corpus file")
 	dictPath := flag.String("dict", "data/dict_base.txt", "Path to dictionary file (optional, each word becomes a training sentence)")
-	outputPath := flag.String("output", "data/model.crf", "Path to save the model")
+	outputPath := flag.String("output", "data/model.crf", "Path to save the model (data/ner.crf with -ner)")
 	iter := flag.Int("iter", 10, "Number of training iterations")
+	threads := flag.Int("threads", 1, "Number of training threads (>1 enables sharded mini-batch training)")
+	seed := flag.Int64("seed", 0, "Shuffle seed for the training set (0 keeps corpus order)")
+	nerMode := flag.Bool("ner", false, "Train a BIO entity model from a CoNLL-style corpus (one \"char label\" per line)")
 	flag.Parse()
 
 	if *inputPath == "" {
@@ -20,13 +23,36 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *nerMode {
+		// Never overwrite the segmentation model with an entity model by default.
+		outputSet := false
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "output" {
+				outputSet = true
+			}
+		})
+		if !outputSet {
+			*outputPath = "data/ner.crf"
+		}
+		fmt.Printf("Training NER model...\n")
+		fmt.Printf("Input: %s\n", *inputPath)
+		fmt.Printf("Output: %s\n", *outputPath)
+		if err := optimizer.TrainNER(*inputPath, *outputPath, *iter, *threads, *seed); err != nil {
+			fmt.Fprintf(os.Stderr, "Training failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Successfully saved model to %s\n", *outputPath)
+		return
+	}
+
 	fmt.Printf("Training CRF model...\n")
 	fmt.Printf("Input: %s\n", *inputPath)
 	fmt.Printf("Dict Overlay: %s\n", *dictPath)
 	fmt.Printf("Output: %s\n", *outputPath)
 	fmt.Printf("Iterations: %d\n", *iter)
+	fmt.Printf("Threads: %d\n", *threads)
 
-	err := optimizer.TrainCRF(*inputPath, *dictPath, *outputPath, *iter)
+	err := optimizer.TrainCRFParallel(*inputPath, *dictPath, *outputPath, *iter, *threads, *seed)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Training failed: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,157 @@
+package crf
+
+import (
+	"fmt"
+	"math"
+)
+
+// PruneWeights removes every feature weight whose absolute value is below minAbs.
+// It returns the number of removed weights.
+func (m *Model) PruneWeights(minAbs float64) int {
+	m.unpack()
+	defer m.pack()
+	removed := 0
+	for feat, weights := range m.Feats {
+		for tag, w := range weights {
+			if math.Abs(w) < minAbs {
+				delete(weights, tag)
+				removed++
+			}
+		}
+		if len(weights) == 0 {
+			delete(m.Feats, feat)
+		}
+	}
+	return removed
+}
+
+// FeatureCounts counts how often every feature fires in the sentences.
+func FeatureCounts(sents []Sentence) map[string]int {
+	counts := make(map[string]int)
+	for _, sent := range sents {
+		for i := range sent.Runes {
+			for _, f := range ExtractFeatures(sent.Runes, i) {
+				counts[f]++
+			}
+		}
+	}
+	return counts
+}
+
+// PruneFeatures removes every feature seen fewer than minCount times according to counts.
+// It returns the number of removed features.
+func (m *Model) PruneFeatures(counts map[string]int, minCount int) int {
+	m.unpack()
+	defer m.pack()
+	removed := 0
+	for feat := range m.Feats {
+		if counts[feat] < minCount {
+			delete(m.Feats, feat)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Quantize snaps every feature weight to a signed integer grid of the given bit width
+// (8 or 16), and the model keeps the weights as int8 or int16 codes, in memory as in the
+// file that Save writes: a few bytes per weight instead of a map per feature, so a loaded
+// quantized model is also small. Weights that round to zero are dropped. Transition
+// weights are few and stay at full precision. Any later update (Perceptron,
+// PassiveAggressive, UpdateFeat) returns the model to full precision.
+func (m *Model) Quantize(bits int) error {
+	if bits < 2 || bits > 16 {
+		return fmt.Errorf("unsupported quantization width: %d bits", bits)
+	}
+	m.unpack()
+	maxAbs := 0.0
+	for _, weights := range m.Feats {
+		for _, w := range weights {
+			maxAbs = math.Max(maxAbs, math.Abs(w))
+		}
+	}
+	if maxAbs == 0 {
+		return nil
+	}
+
+	levels := float64(int64(1)<<(bits-1) - 1)
+	m.Scale = maxAbs / levels
+	for feat, weights := range m.Feats {
+		for tag, w := range weights {
+			code := math.Round(w / m.Scale)
+			if code == 0 {
+				delete(weights, tag)
+				continue
+			}
+			weights[tag] = code * m.Scale
+		}
+		if len(weights) == 0 {
+			delete(m.Feats, feat)
+		}
+	}
+	m.pack()
+	return nil
+}
+
+// EvalResult holds segmentation quality of a model on a gold corpus.
+type EvalResult struct {
+	TagAccuracy float64
+	Precision   float64
+	Recall      float64
+	F1          float64
+}
+
+// Evaluate decodes every sentence and compares the result with its gold tags.
+func (m *Model) Evaluate(sents []Sentence) EvalResult {
+	correctTags, totalTags := 0, 0
+	correctWords, predWords, goldWords := 0, 0, 0
+	for _, sent := range sents {
+		pred := m.Decode(sent.Runes)
+		for i := range pred {
+			if pred[i] == sent.Tags[i] {
+				correctTags++
+			}
+			totalTags++
+		}
+
+		gold := wordSpans(sent.Tags)
+		for span := range wordSpans(pred) {
+			if gold[span] {
+				correctWords++
+			}
+			predWords++
+		}
+		goldWords += len(gold)
+	}
+
+	var r EvalResult
+	if totalTags > 0 {
+		r.TagAccuracy = float64(correctTags) / float64(totalTags)
+	}
+	if predWords > 0 {
+		r.Precision = float64(correctWords) / float64(predWords)
+	}
+	if goldWords > 0 {
+		r.Recall = float64(correctWords) / float64(goldWords)
+	}
+	if r.Precision+r.Recall > 0 {
+		r.F1 = 2 * r.Precision * r.Recall / (r.Precision + r.Recall)
+	}
+	return r
+}
+
+// wordSpans returns the [start, end) rune ranges of the words described by a tag sequence.
+func wordSpans(tags []int) map[[2]int]bool {
+	spans := make(map[[2]int]bool)
+	start := 0
+	for i, tag := range tags {
+		if tag == TagB || tag == TagS {
+			start = i
+		}
+		if tag == TagE || tag == TagS || i == len(tags)-1 {
+			spans[[2]int{start, i + 1}] = true
+			start = i + 1
+		}
+	}
+	return spans
+}
@@ -1,7 +1,11 @@
 package crf
 
 import (
+	"math"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -36,3 +40,300 @@ func TestDecode(t *testing.T) {
 		t.Errorf("Decode('AB') = %v, want %v", got, expected)
 	}
 }
+
+func TestDecode_Grammar(t *testing.T) {
+	m := NewModel()
+	// Emissions alone would prefer "M M" which is not a valid BMES sequence.
+	m.Feats["U02:A"] = map[int]float64{TagM: 10.0, TagB: 1.0}
+	m.Feats["U02:B"] = map[int]float64{TagM: 10.0, TagE: 1.0}
+
+	got := m.Decode([]rune("AB"))
+	expected := []int{TagB, TagE}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Decode('AB') = %v, want %v", got, expected)
+	}
+
+	// A single character can only be tagged S.
+	if got := m.Decode([]rune("A")); !reflect.DeepEqual(got, []int{TagS}) {
+		t.Errorf("Decode('A') = %v, want [S]", got)
+	}
+}
+
+func TestDecode_StartEnd(t *testing.T) {
+	m := NewModel()
+	m.Feats["U02:A"] = map[int]float64{TagB: 1.0}
+	m.Feats["U02:B"] = map[int]float64{TagE: 1.0}
+	// A strong end transition for S overrides the emissions.
+	m.Start[TagS] = 5.0
+	m.End[TagS] = 5.0
+
+	got := m.Decode([]rune("AB"))
+	expected := []int{TagS, TagS}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Decode('AB') = %v, want %v", got, expected)
+	}
+}
+
+func TestMarginals(t *testing.T) {
+	m := NewModel()
+	m.Feats["U02:A"] = map[int]float64{TagB: 1.0}
+	m.Feats["U02:B"] = map[int]float64{TagE: 1.0}
+
+	// Only "B E" (score 2) and "S S" (score 0) are valid for two characters.
+	got := m.Marginals([]rune("AB"))
+	pBE := math.Exp(2) / (math.Exp(2) + 1)
+	for i, want := range [][]float64{{pBE, 0, 0, 1 - pBE}, {0, 0, pBE, 1 - pBE}} {
+		for tag := range want {
+			if math.Abs(got[i][tag]-want[tag]) > 1e-9 {
+				t.Errorf("Marginals()[%d] = %v, want %v", i, got[i], want)
+				break
+			}
+		}
+	}
+}
+
+func TestModel_SaveLoadStartEnd(t *testing.T) {
+	m := NewModel()
+	m.Trans[TagB][TagE] = 2.0
+	m.Start[TagB] = 1.5
+	m.End[TagE] = -0.5
+
+	path := filepath.Join(t.TempDir(), "model.crf")
+	if err := m.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewModel()
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Start, m.Start) || !reflect.DeepEqual(loaded.End, m.End) || !reflect.DeepEqual(loaded.Trans, m.Trans) {
+		t.Errorf("round trip mismatch: start=%v end=%v trans=%v", loaded.Start, loaded.End, loaded.Trans)
+	}
+}
+
+func TestImportCRFPP(t *testing.T) {
+	// Labels are listed in CRF++ (alphabetical) order: B E M S.
+	content := `version: 100
+cost-factor: 1
+maxid: 28
+xsize: 1
+
+B
+E
+M
+S
+
+U00:%x[-1,0]
+U01:%x[0,0]
+U02:%x[-1,0]/%x[0,0]
+B
+
+0 B
+16 U01:A
+20 U00:_B-1
+24 U02:_B-1/A
+
+` + "0\n0\n2.5\n0\n" + strings.Repeat("0\n", 12) +
+		"1.5\n0\n0\n-1\n" + "0.5\n0\n0\n0\n" + "9\n9\n9\n9\n"
+
+	path := filepath.Join(t.TempDir(), "model.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ImportCRFPP(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Index 2 is prev=B(0), curr=M(2).
+	if m.Trans[TagB][TagM] != 2.5 {
+		t.Errorf("Trans[B][M] = %v, want 2.5", m.Trans[TagB][TagM])
+	}
+	// U01 is the current character, which is our U02 template.
+	if w := m.Feats["U02:A"]; w[TagB] != 1.5 || w[TagS] != -1 {
+		t.Errorf("Feats[U02:A] = %v", w)
+	}
+	if w := m.Feats["U01:_BOS_"]; w[TagB] != 0.5 {
+		t.Errorf("Feats[U01:_BOS_] = %v", w)
+	}
+	// Compound templates are not representable and must be skipped.
+	if len(m.Feats) != 2 {
+		t.Errorf("len(Feats) = %d, want 2", len(m.Feats))
+	}
+}
+
+func TestPassiveAggressive(t *testing.T) {
+	m := NewModel()
+	m.Feats["U02:A"] = map[int]float64{TagS: 2.0}
+	m.Feats["U02:B"] = map[int]float64{TagS: 2.0}
+
+	sent := SentenceFromWords([]string{"AB"})
+	if got := m.Decode(sent.Runes); reflect.DeepEqual(got, sent.Tags) {
+		t.Fatalf("model already predicts %v", got)
+	}
+
+	orig := m.Clone()
+	if !m.PassiveAggressive(sent, 10.0) {
+		t.Fatal("expected an update")
+	}
+	if got := m.Decode(sent.Runes); !reflect.DeepEqual(got, sent.Tags) {
+		t.Errorf("after update Decode = %v, want %v", got, sent.Tags)
+	}
+	if orig.Feats["U02:A"][TagS] != 2.0 || len(orig.Feats) != 2 {
+		t.Errorf("Clone shares state with the updated model: %v", orig.Feats)
+	}
+	if m.PassiveAggressive(sent, 10.0) {
+		t.Error("no update expected once the sentence is predicted correctly")
+	}
+}
+
+func TestTrainBatch_Deterministic(t *testing.T) {
+	var sents []Sentence
+	for _, line := range []string{"南京 市长 江 大桥", "长江 大桥", "南京市 长江大桥", "我 是 程序员", "程序 员"} {
+		sents = append(sents, SentenceFromWords(strings.Fields(line)))
+	}
+
+	train := func() *Model {
+		m := NewModel()
+		for it := 0; it < 5; it++ {
+			m.TrainBatch(sents, 3, 1.0)
+		}
+		return m
+	}
+
+	a, b := train(), train()
+	if !reflect.DeepEqual(a, b) {
+		t.Error("TrainBatch produced different models for the same data and thread count")
+	}
+	if len(a.Feats) == 0 {
+		t.Error("TrainBatch did not update the model")
+	}
+}
+
+func TestDiffModels(t *testing.T) {
+	from := NewModel()
+	from.Trans[TagB][TagE] = 1.0
+	from.Feats["U02:A"] = map[int]float64{TagB: 1.0, TagS: -1.0}
+	from.Feats["U02:B"] = map[int]float64{TagE: 2.0}
+
+	to := from.Clone()
+	to.Trans[TagB][TagE] = 3.0
+	to.UpdateFeat("U02:A", TagB, 5.0)
+	to.UpdateFeat("U02:C", TagS, 0.5)
+	delete(to.Feats, "U02:B")
+
+	d := DiffModels(from, to)
+	if !reflect.DeepEqual(d.Added, []string{"U02:C"}) || !reflect.DeepEqual(d.Removed, []string{"U02:B"}) {
+		t.Errorf("Added = %v, Removed = %v", d.Added, d.Removed)
+	}
+	if d.Trans[TagB][TagE] != 2.0 {
+		t.Errorf("Trans delta = %v, want 2", d.Trans[TagB][TagE])
+	}
+	want := []WeightChange{
+		{"U02:A", TagB, 1.0, 6.0},
+		{"U02:B", TagE, 2.0, 0},
+		{"U02:C", TagS, 0, 0.5},
+	}
+	if !reflect.DeepEqual(d.Changed, want) {
+		t.Errorf("Changed = %v, want %v", d.Changed, want)
+	}
+
+	s := to.Summarize()
+	if s.Features != 2 || s.Weights != 3 || s.Templates["U02"] != 2 || s.Min != -1 || s.Max != 6 {
+		t.Errorf("Summarize() = %+v", s)
+	}
+	if top := to.TopFeatures(TagB, 1); len(top) != 1 || top[0].Feature != "U02:A" {
+		t.Errorf("TopFeatures(B) = %v", top)
+	}
+}
+
+func TestPruneAndQuantize(t *testing.T) {
+	m := NewModel()
+	m.Feats["U02:A"] = map[int]float64{TagB: 10.0, TagS: 0.2}
+	m.Feats["U02:B"] = map[int]float64{TagE: -5.0}
+	m.Feats["U02:C"] = map[int]float64{TagS: 0.1}
+
+	if removed := m.PruneWeights(0.5); removed != 2 {
+		t.Errorf("PruneWeights removed %d, want 2", removed)
+	}
+	if _, ok := m.Feats["U02:C"]; ok {
+		t.Error("feature without weights should be removed")
+	}
+
+	counts := FeatureCounts([]Sentence{SentenceFromWords([]string{"A"})})
+	pruned := m.Clone()
+	if removed := pruned.PruneFeatures(counts, 1); removed != 1 || pruned.Feats["U02:A"] == nil {
+		t.Errorf("PruneFeatures removed %d, feats = %v", removed, pruned.Feats)
+	}
+
+	if err := m.Quantize(8); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "model.crf")
+	if err := m.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewModel()
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Weight("U02:B", TagE); math.Abs(got-(-5.0)) > m.Scale/2 {
+		t.Errorf("quantized weight = %v, want ~ -5.0", got)
+	}
+	if loaded.Weight("U02:A", TagB) != 10.0 {
+		t.Errorf("max weight = %v, want exactly 10.0", loaded.Weight("U02:A", TagB))
+	}
+	// The weights stay packed in memory; decoding and Clone read them as before.
+	if loaded.Feats != nil || loaded.Clone().Weight("U02:B", TagE) != loaded.Weight("U02:B", TagE) {
+		t.Errorf("loaded quantized model: Feats = %v", loaded.Feats)
+	}
+	if got := loaded.Decode([]rune("AB")); !reflect.DeepEqual(got, m.Decode([]rune("AB"))) {
+		t.Errorf("Decode() of the loaded model = %v", got)
+	}
+	if d := DiffModels(m, loaded); len(d.Changed) != 0 || len(d.Added) != 0 || len(d.Removed) != 0 {
+		t.Errorf("DiffModels(saved, loaded) = %+v", d)
+	}
+
+	// An update leaves the grid: the model is saved at full precision again.
+	loaded.UpdateFeat("U02:B", TagE, 0.01)
+	if loaded.Scale != 0 {
+		t.Errorf("Scale after update = %v, want 0", loaded.Scale)
+	}
+	if err := loaded.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewModel()
+	if err := reloaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	want := loaded.Weight("U02:B", TagE)
+	if got := reloaded.Weight("U02:B", TagE); math.Abs(got-want) > 1e-4 {
+		t.Errorf("weight after update = %v, want %v", got, want)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	m := NewModel()
+	m.Feats["U02:A"] = map[int]float64{TagB: 1.0}
+	m.Feats["U02:B"] = map[int]float64{TagE: 1.0}
+	m.Feats["U02:C"] = map[int]float64{TagB: 1.0}
+	m.Feats["U02:D"] = map[int]float64{TagE: 1.0}
+
+	// Predicted "AB CD" against gold "AB C D": one of two words correct, two of three gold words missed.
+	r := m.Evaluate([]Sentence{SentenceFromWords([]string{"AB", "C", "D"})})
+	if r.TagAccuracy != 0.5 || r.Precision != 0.5 || math.Abs(r.Recall-1.0/3) > 1e-9 {
+		t.Errorf("Evaluate() = %+v", r)
+	}
+}
+
+func TestExtractFeatures_RareHan(t *testing.T) {
+	// Extension B (𠮷), Extension A (㐀) and compatibility ideographs share the class of common Han.
+	got := ExtractFeatures([]rune("𠮷野家"), 0)
+	if got[5] != "U05:_/H/H" {
+		t.Errorf("class feature = %q, want U05:_/H/H", got[5])
+	}
+	got = ExtractFeatures([]rune("a㐀，"), 1)
+	if got[5] != "U05:L/H/P" {
+		t.Errorf("class feature = %q, want U05:L/H/P", got[5])
+	}
+}
@@ -2,60 +2,167 @@ package crf
 
 import (
 	"math"
+	"strings"
 )
 
+// validTrans[from][to] reports whether the BMES grammar allows tag "to" to follow tag "from".
+var validTrans = [4][4]bool{
+	TagB: {TagM: true, TagE: true},
+	TagM: {TagM: true, TagE: true},
+	TagE: {TagB: true, TagS: true},
+	TagS: {TagB: true, TagS: true},
+}
+
+// ValidTransition reports whether tag "to" may follow tag "from" in a BMES sequence.
+func ValidTransition(from, to int) bool {
+	return validTrans[from][to]
+}
+
+// ValidStart reports whether a BMES sequence may begin with the tag.
+func ValidStart(tag int) bool {
+	return tag == TagB || tag == TagS
+}
+
+// ValidEnd reports whether a BMES sequence may end with the tag.
+func ValidEnd(tag int) bool {
+	return tag == TagE || tag == TagS
+}
+
+type grammarKind int
+
+const (
+	grammarFree grammarKind = iota // any tag may follow any tag
+	grammarBMES                    // word segmentation: B M* E | S
+	grammarBIO                     // entity tagging: O | B-X I-X*
+)
+
+// grammar holds the tag sequences a model is allowed to produce, derived from its labels.
+type grammar struct {
+	kind  grammarKind
+	trans [][]bool
+	start []bool
+	end   []bool
+}
+
+func newGrammar(labels []string) grammar {
+	n := len(labels)
+	g := grammar{kind: grammarFree}
+	switch {
+	case strings.Join(labels, " ") == strings.Join(SegLabels, " "):
+		g.kind = grammarBMES
+	case isBIO(labels):
+		g.kind = grammarBIO
+	}
+
+	g.trans = make([][]bool, n)
+	g.start = make([]bool, n)
+	g.end = make([]bool, n)
+	for from := 0; from < n; from++ {
+		g.trans[from] = make([]bool, n)
+		for to := 0; to < n; to++ {
+			switch g.kind {
+			case grammarBMES:
+				g.trans[from][to] = validTrans[from][to]
+			case grammarBIO:
+				// I-X may only continue B-X or I-X.
+				typ, inside := strings.CutPrefix(labels[to], "I-")
+				g.trans[from][to] = !inside || labels[from] == "B-"+typ || labels[from] == "I-"+typ
+			default:
+				g.trans[from][to] = true
+			}
+		}
+		switch g.kind {
+		case grammarBMES:
+			g.start[from], g.end[from] = ValidStart(from), ValidEnd(from)
+		case grammarBIO:
+			g.start[from], g.end[from] = !strings.HasPrefix(labels[from], "I-"), true
+		default:
+			g.start[from], g.end[from] = true, true
+		}
+	}
+	return g
+}
+
+func isBIO(labels []string) bool {
+	hasO := false
+	for _, l := range labels {
+		switch {
+		case l == "O":
+			hasO = true
+		case strings.HasPrefix(l, "B-"), strings.HasPrefix(l, "I-"):
+		default:
+			return false
+		}
+	}
+	return hasO
+}
+
 // Decode performs Viterbi decoding to find the best tag sequence.
+// Only paths allowed by the label grammar are considered: a BMES result always
+// starts with B/S, ends with E/S and never contains e.g. "S M"; a BIO result never
+// starts an entity with I-X.
 func (m *Model) Decode(runes []rune) []int {
 	n := len(runes)
 	if n == 0 {
 		return []int{}
 	}
 
+	numTags := m.NumTags()
+	g := m.grammar
+	negInf := math.Inf(-1)
+
 	// dp[i][tag] = max score ending at i with tag
-	dp := make([][4]float64, n)
+	dp := make([][]float64, n)
 	// path[i][tag] = previous tag that gave max score
-	path := make([][4]int, n)
-
-	// Initialization (t=0)
-	for tag := 0; tag < 4; tag++ {
-		// Base score from features
-		emission := m.computeEmission(runes, 0, tag)
-		// Initial transition prob (simplified, assume transition from Start to tag)
-		// Usually we have a specific start state, here we can assume B or S is likely start.
-		// Or just 0 if we assume uniform start.
-		// Let's rely on emission and let transition handle i>0.
-		// Or assume previous is "End" (TagE or TagS) effectively.
-		// For simplicity, 0.
-		dp[0][tag] = emission
+	path := make([][]int, n)
+	for i := range dp {
+		dp[i] = make([]float64, numTags)
+		path[i] = make([]int, numTags)
+	}
+
+	// Initialization (t=0): BOS -> tag
+	for tag := 0; tag < numTags; tag++ {
+		if !g.start[tag] {
+			dp[0][tag] = negInf
+			continue
+		}
+		dp[0][tag] = m.Start[tag] + m.computeEmission(runes, 0, tag)
 	}
 
 	// Recurrence
 	for i := 1; i < n; i++ {
-		for curr := 0; curr < 4; curr++ {
-			maxScore := -math.MaxFloat64
+		for curr := 0; curr < numTags; curr++ {
+			maxScore := negInf
 			bestPrev := -1
 
-			emission := m.computeEmission(runes, i, curr)
-
-			for prev := 0; prev < 4; prev++ {
-				score := dp[i-1][prev] + m.Trans[prev][curr] + emission
+			for prev := 0; prev < numTags; prev++ {
+				if !g.trans[prev][curr] || dp[i-1][prev] == negInf {
+					continue
+				}
+				score := dp[i-1][prev] + m.Trans[prev][curr]
 				if score > maxScore {
 					maxScore = score
 					bestPrev = prev
 				}
 			}
+			if bestPrev >= 0 {
+				maxScore += m.computeEmission(runes, i, curr)
+			}
 			dp[i][curr] = maxScore
 			path[i][curr] = bestPrev
 		}
 	}
 
-	// Termination
-	maxScore := -math.MaxFloat64
+	// Termination: tag -> EOS
+	maxScore := negInf
 	bestEnd := -1
-	for tag := 0; tag < 4; tag++ {
-		// Could add transition to STOP state here if model supports it.
-		if dp[n-1][tag] > maxScore {
-			maxScore = dp[n-1][tag]
+	for tag := 0; tag < numTags; tag++ {
+		if !g.end[tag] || dp[n-1][tag] == negInf {
+			continue
+		}
+		score := dp[n-1][tag] + m.End[tag]
+		if score > maxScore {
+			maxScore = score
 			bestEnd = tag
 		}
 	}
@@ -70,17 +177,26 @@ func (m *Model) Decode(runes []rune) []int {
 	return tags
 }
 
+// Emissions returns the emission score of every tag at every position of runes, the sum of
+// the weights of the features that fire there, as used by Decode.
+func (m *Model) Emissions(runes []rune) [][]float64 {
+	scores := make([][]float64, len(runes))
+	for i := range runes {
+		scores[i] = make([]float64, m.NumTags())
+		for tag := range scores[i] {
+			scores[i][tag] = m.computeEmission(runes, i, tag)
+		}
+	}
+	return scores
+}
+
 func (m *Model) computeEmission(runes []rune, idx int, tag int) float64 {
 	score := 0.0
 	// Feature templates
 	features := ExtractFeatures(runes, idx)
 
 	for _, feat := range features {
-		if weights, ok := m.Feats[feat]; ok {
-			if w, ok := weights[tag]; ok {
-				score += w
-			}
-		}
+		score += m.Weight(feat, tag)
 	}
 	return score
 }
@@ -1,5 +1,7 @@
 package crf
 
+import "unicode"
+
 // ExtractFeatures generates feature strings for a character at a given index in a sequence.
 func ExtractFeatures(runes []rune, idx int) []string {
 	// Helper to safely get char
@@ -17,11 +19,35 @@ func ExtractFeatures(runes []rune, idx int) []string {
 	// U02: x[i]
 	// U03: x[i+1]
 	// U04: x[i+2]
+	// U05: class(x[i-1])/class(x[i])/class(x[i+1])
 	return []string{
 		"U00:" + getChar(-2),
 		"U01:" + getChar(-1),
 		"U02:" + getChar(0),
 		"U03:" + getChar(1),
 		"U04:" + getChar(2),
+		"U05:" + charClass(runes, idx-1) + "/" + charClass(runes, idx) + "/" + charClass(runes, idx+1),
+	}
+}
+
+// charClass names the kind of character at pos. Rare Han characters (Extension A-G,
+// compatibility ideographs) seldom occur in training data, so the class is what lets
+// the model treat 𠮷 like 吉 instead of like punctuation.
+func charClass(runes []rune, pos int) string {
+	if pos < 0 || pos >= len(runes) {
+		return "_"
+	}
+	r := runes[pos]
+	switch {
+	case unicode.Is(unicode.Han, r):
+		return "H"
+	case r < 128 && unicode.IsDigit(r):
+		return "D"
+	case r < 128 && unicode.IsLetter(r):
+		return "L"
+	case unicode.IsLetter(r) || unicode.IsNumber(r):
+		return "W"
+	default:
+		return "P"
 	}
 }
@@ -0,0 +1,140 @@
+package crf
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// crfppUnigram matches a CRF++ unigram template over the character column, e.g. "U01:%x[-1,0]".
+var crfppUnigram = regexp.MustCompile(`^(U[^:]*):%x\[(-?\d+),0\]$`)
+
+// ImportCRFPP converts a CRF++ text model (as written by "crf_learn -t") into a Model.
+// Only unigram templates over a single character at offsets -2..2 and the plain "B"
+// bigram template can be represented; other templates are skipped.
+// Labels must be BMES (I is accepted as an alias of M).
+func ImportCRFPP(path string) (*Model, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	buf := make([]byte, 1024*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	// The text model is made of blank-line separated sections:
+	// header, labels, templates, feature index and finally the weights.
+	var sections [][]string
+	var current []string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			if len(current) > 0 && len(sections) < 4 {
+				sections = append(sections, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(current) > 0 {
+		sections = append(sections, current)
+	}
+	if len(sections) < 5 || !strings.HasPrefix(sections[0][0], "version:") {
+		return nil, fmt.Errorf("%s is not a CRF++ text model", path)
+	}
+	labelLines, templateLines, featureLines, weightLines := sections[1], sections[2], sections[3], sections[4]
+
+	labels := make([]int, len(labelLines))
+	for i, l := range labelLines {
+		tag := parseTag(l)
+		if l == "I" {
+			tag = TagM
+		}
+		if tag < 0 {
+			return nil, fmt.Errorf("unsupported CRF++ label %q", l)
+		}
+		labels[i] = tag
+	}
+	numLabels := len(labels)
+
+	// Map CRF++ template ids to our own feature prefixes by character offset.
+	prefixes := make(map[string]string)
+	for _, t := range templateLines {
+		match := crfppUnigram.FindStringSubmatch(t)
+		if match == nil {
+			continue
+		}
+		offset, _ := strconv.Atoi(match[2])
+		if offset < -2 || offset > 2 {
+			continue
+		}
+		prefixes[match[1]] = fmt.Sprintf("U%02d", offset+2)
+	}
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("no compatible unigram templates in %s", path)
+	}
+
+	weights := make([]float64, len(weightLines))
+	for i, l := range weightLines {
+		w, err := strconv.ParseFloat(l, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad weight on line %q: %w", l, err)
+		}
+		weights[i] = w
+	}
+	weight := func(idx int) float64 {
+		if idx < len(weights) {
+			return weights[idx]
+		}
+		return 0
+	}
+
+	m := NewModel()
+	for _, l := range featureLines {
+		idStr, feat, ok := strings.Cut(l, " ")
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			continue
+		}
+
+		if feat == "B" {
+			for p := 0; p < numLabels; p++ {
+				for c := 0; c < numLabels; c++ {
+					m.Trans[labels[p]][labels[c]] += weight(id + p*numLabels + c)
+				}
+			}
+			continue
+		}
+
+		tmplID, value, ok := strings.Cut(feat, ":")
+		if !ok {
+			continue
+		}
+		prefix, ok := prefixes[tmplID]
+		if !ok {
+			continue
+		}
+		// CRF++ marks out-of-range positions as _B-1, _B+1, ...
+		if strings.HasPrefix(value, "_B-") || strings.HasPrefix(value, "_B+") {
+			value = "_BOS_"
+		}
+		for y := 0; y < numLabels; y++ {
+			if w := weight(id + y); w != 0 {
+				m.UpdateFeat(prefix+":"+value, labels[y], w)
+			}
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,173 @@
+package crf
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// FeatureWeight is a single (feature, tag) weight of a model.
+type FeatureWeight struct {
+	Feature string
+	Tag     int
+	Weight  float64
+}
+
+// Summary describes the size and weight distribution of a model.
+type Summary struct {
+	Features   int            // distinct feature strings
+	Weights    int            // non-zero (feature, tag) weights
+	Templates  map[string]int // feature strings per template, e.g. "U02"
+	TagWeights []int          // non-zero weights per tag
+	Min, Max   float64
+}
+
+// Summarize counts features and weights of the model.
+func (m *Model) Summarize() Summary {
+	s := Summary{Templates: make(map[string]int), TagWeights: make([]int, m.NumTags())}
+	first := true
+	for feat, weights := range m.features() {
+		s.Features++
+		tmpl, _, _ := strings.Cut(feat, ":")
+		s.Templates[tmpl]++
+		for tag, w := range weights {
+			if w == 0 {
+				continue
+			}
+			s.Weights++
+			s.TagWeights[tag]++
+			if first || w < s.Min {
+				s.Min = w
+			}
+			if first || w > s.Max {
+				s.Max = w
+			}
+			first = false
+		}
+	}
+	return s
+}
+
+// TopFeatures returns the k features with the highest weight for the tag.
+func (m *Model) TopFeatures(tag, k int) []FeatureWeight {
+	var res []FeatureWeight
+	for feat, weights := range m.features() {
+		if w, ok := weights[tag]; ok && w != 0 {
+			res = append(res, FeatureWeight{feat, tag, w})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Weight != res[j].Weight {
+			return res[i].Weight > res[j].Weight
+		}
+		return res[i].Feature < res[j].Feature
+	})
+	if len(res) > k {
+		res = res[:k]
+	}
+	return res
+}
+
+// Histogram buckets all non-zero feature weights into bins of equal width between the
+// smallest and largest weight. It returns the lower edge of every bin and its count.
+func (m *Model) Histogram(bins int) ([]float64, []int) {
+	s := m.Summarize()
+	if bins <= 0 || s.Weights == 0 {
+		return nil, nil
+	}
+	width := (s.Max - s.Min) / float64(bins)
+	edges := make([]float64, bins)
+	for i := range edges {
+		edges[i] = s.Min + float64(i)*width
+	}
+	counts := make([]int, bins)
+	for _, weights := range m.features() {
+		for _, w := range weights {
+			if w == 0 {
+				continue
+			}
+			idx := bins - 1
+			if width > 0 {
+				idx = min(int((w-s.Min)/width), bins-1)
+			}
+			counts[idx]++
+		}
+	}
+	return edges, counts
+}
+
+// WeightChange is a (feature, tag) weight that differs between two models.
+type WeightChange struct {
+	Feature  string
+	Tag      int
+	Old, New float64
+}
+
+// Delta returns New - Old.
+func (c WeightChange) Delta() float64 {
+	return c.New - c.Old
+}
+
+// ModelDiff describes what changed from one model to another.
+type ModelDiff struct {
+	Added   []string       // features only in the new model
+	Removed []string       // features only in the old model
+	Changed []WeightChange // every differing weight, largest absolute change first
+	Trans   [][]float64    // new - old transition weights
+	Start   []float64      // new - old start weights
+	End     []float64      // new - old end weights
+}
+
+// DiffModels compares two models. Transition deltas are left nil when the label sets differ.
+func DiffModels(from, to *Model) ModelDiff {
+	var d ModelDiff
+	fromFeats, toFeats := from.features(), to.features()
+	for feat, oldWeights := range fromFeats {
+		newWeights, ok := toFeats[feat]
+		if !ok {
+			d.Removed = append(d.Removed, feat)
+		}
+		for tag, w := range oldWeights {
+			if nw := newWeights[tag]; nw != w {
+				d.Changed = append(d.Changed, WeightChange{feat, tag, w, nw})
+			}
+		}
+	}
+	for feat, newWeights := range toFeats {
+		oldWeights, ok := fromFeats[feat]
+		if !ok {
+			d.Added = append(d.Added, feat)
+		}
+		for tag, w := range newWeights {
+			if _, seen := oldWeights[tag]; !seen && w != 0 {
+				d.Changed = append(d.Changed, WeightChange{feat, tag, 0, w})
+			}
+		}
+	}
+	// Transition tables are only comparable between models over the same labels.
+	if strings.Join(from.Labels, " ") == strings.Join(to.Labels, " ") {
+		diff := newEmptyDelta(to.NumTags())
+		for i := range diff.trans {
+			for j := range diff.trans[i] {
+				diff.trans[i][j] = to.Trans[i][j] - from.Trans[i][j]
+			}
+			diff.start[i] = to.Start[i] - from.Start[i]
+			diff.end[i] = to.End[i] - from.End[i]
+		}
+		d.Trans, d.Start, d.End = diff.trans, diff.start, diff.end
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Slice(d.Changed, func(i, j int) bool {
+		a, b := math.Abs(d.Changed[i].Delta()), math.Abs(d.Changed[j].Delta())
+		if a != b {
+			return a > b
+		}
+		if d.Changed[i].Feature != d.Changed[j].Feature {
+			return d.Changed[i].Feature < d.Changed[j].Feature
+		}
+		return d.Changed[i].Tag < d.Changed[j].Tag
+	})
+	return d
+}
@@ -0,0 +1,186 @@
+package crf
+
+// delta holds phi(gold) - phi(pred), the feature difference between two tag sequences.
+type delta struct {
+	feats map[string][]float64
+	trans [][]float64
+	start []float64
+	end   []float64
+}
+
+func newEmptyDelta(numTags int) *delta {
+	d := &delta{
+		feats: make(map[string][]float64),
+		trans: make([][]float64, numTags),
+		start: make([]float64, numTags),
+		end:   make([]float64, numTags),
+	}
+	for i := range d.trans {
+		d.trans[i] = make([]float64, numTags)
+	}
+	return d
+}
+
+func newDelta(numTags int, runes []rune, gold, pred []int) *delta {
+	d := newEmptyDelta(numTags)
+	n := len(runes)
+	for i := 0; i < n; i++ {
+		if gold[i] == pred[i] {
+			continue
+		}
+		for _, f := range ExtractFeatures(runes, i) {
+			d.feat(f)[gold[i]] += 1.0
+			d.feat(f)[pred[i]] -= 1.0
+		}
+	}
+	for i := 1; i < n; i++ {
+		d.trans[gold[i-1]][gold[i]] += 1.0
+		d.trans[pred[i-1]][pred[i]] -= 1.0
+	}
+	d.start[gold[0]] += 1.0
+	d.start[pred[0]] -= 1.0
+	d.end[gold[n-1]] += 1.0
+	d.end[pred[n-1]] -= 1.0
+	return d
+}
+
+// feat returns the per-tag difference of a feature, allocating it on first use.
+func (d *delta) feat(f string) []float64 {
+	w := d.feats[f]
+	if w == nil {
+		w = make([]float64, len(d.start))
+		d.feats[f] = w
+	}
+	return w
+}
+
+// norm2 returns the squared L2 norm of the difference vector.
+func (d *delta) norm2() float64 {
+	sum := 0.0
+	for _, w := range d.feats {
+		for _, v := range w {
+			sum += v * v
+		}
+	}
+	for i := range d.trans {
+		for j := range d.trans[i] {
+			sum += d.trans[i][j] * d.trans[i][j]
+		}
+		sum += d.start[i]*d.start[i] + d.end[i]*d.end[i]
+	}
+	return sum
+}
+
+// apply adds scale * delta to the model weights.
+func (d *delta) apply(m *Model, scale float64) {
+	for f, w := range d.feats {
+		for tag, v := range w {
+			if v != 0 {
+				m.UpdateFeat(f, tag, scale*v)
+			}
+		}
+	}
+	for i := range d.trans {
+		for j := range d.trans[i] {
+			m.Trans[i][j] += scale * d.trans[i][j]
+		}
+		m.Start[i] += scale * d.start[i]
+		m.End[i] += scale * d.end[i]
+	}
+}
+
+// Score returns the total score of a tag sequence under the model.
+func (m *Model) Score(runes []rune, tags []int) float64 {
+	n := len(runes)
+	if n == 0 {
+		return 0
+	}
+	score := m.Start[tags[0]] + m.End[tags[n-1]]
+	for i := 0; i < n; i++ {
+		score += m.computeEmission(runes, i, tags[i])
+		if i > 0 {
+			score += m.Trans[tags[i-1]][tags[i]]
+		}
+	}
+	return score
+}
+
+// Perceptron applies a structured perceptron update for one sentence.
+// It reports whether the model mispredicted the sentence (and was therefore updated).
+func (m *Model) Perceptron(sent Sentence, rate float64) bool {
+	pred := m.Decode(sent.Runes)
+	if len(pred) != len(sent.Tags) || equalTags(pred, sent.Tags) {
+		return false
+	}
+	newDelta(m.NumTags(), sent.Runes, sent.Tags, pred).apply(m, rate)
+	return true
+}
+
+// PassiveAggressive applies a PA-I update for one sentence: the smallest step that makes
+// the gold sequence outscore the prediction by its Hamming loss, capped by c.
+// It reports whether the model was updated.
+func (m *Model) PassiveAggressive(sent Sentence, c float64) bool {
+	pred := m.Decode(sent.Runes)
+	if len(pred) != len(sent.Tags) || equalTags(pred, sent.Tags) {
+		return false
+	}
+
+	hamming := 0.0
+	for i := range pred {
+		if pred[i] != sent.Tags[i] {
+			hamming++
+		}
+	}
+	loss := m.Score(sent.Runes, pred) - m.Score(sent.Runes, sent.Tags) + hamming
+	if loss <= 0 {
+		return false
+	}
+
+	d := newDelta(m.NumTags(), sent.Runes, sent.Tags, pred)
+	norm := d.norm2()
+	if norm == 0 {
+		return false
+	}
+	tau := loss / norm
+	if tau > c {
+		tau = c
+	}
+	d.apply(m, tau)
+	return true
+}
+
+// Clone returns a deep copy of the model, so it can be updated while the original is in use.
+func (m *Model) Clone() *Model {
+	c := NewModelWithLabels(m.Labels)
+	for i := range m.Trans {
+		copy(c.Trans[i], m.Trans[i])
+	}
+	copy(c.Start, m.Start)
+	copy(c.End, m.End)
+	c.Scale = m.Scale
+	if m.packed != nil {
+		// Packed weights are never changed in place, see unpack.
+		c.Feats, c.packed = nil, m.packed
+		return c
+	}
+	for f, weights := range m.Feats {
+		w := make(map[int]float64, len(weights))
+		for tag, v := range weights {
+			w[tag] = v
+		}
+		c.Feats[f] = w
+	}
+	return c
+}
+
+func equalTags(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package crf
+
+import "math"
+
+// Marginals returns, for every position of runes, the probability of each tag over all tag
+// sequences allowed by the label grammar (forward-backward). Unlike Decode, which keeps
+// only the best path, it tells how sure the model is of each character.
+func (m *Model) Marginals(runes []rune) [][]float64 {
+	n := len(runes)
+	if n == 0 {
+		return [][]float64{}
+	}
+	numTags := m.NumTags()
+	g := m.grammar
+	negInf := math.Inf(-1)
+	emissions := m.Emissions(runes)
+
+	alpha := make([][]float64, n)
+	beta := make([][]float64, n)
+	for i := range alpha {
+		alpha[i] = make([]float64, numTags)
+		beta[i] = make([]float64, numTags)
+	}
+	for tag := 0; tag < numTags; tag++ {
+		alpha[0][tag] = negInf
+		if g.start[tag] {
+			alpha[0][tag] = m.Start[tag] + emissions[0][tag]
+		}
+		beta[n-1][tag] = negInf
+		if g.end[tag] {
+			beta[n-1][tag] = m.End[tag]
+		}
+	}
+	scores := make([]float64, numTags)
+	for i := 1; i < n; i++ {
+		for curr := 0; curr < numTags; curr++ {
+			for prev := 0; prev < numTags; prev++ {
+				scores[prev] = negInf
+				if g.trans[prev][curr] {
+					scores[prev] = alpha[i-1][prev] + m.Trans[prev][curr]
+				}
+			}
+			alpha[i][curr] = logSumExp(scores) + emissions[i][curr]
+		}
+	}
+	for i := n - 2; i >= 0; i-- {
+		for curr := 0; curr < numTags; curr++ {
+			for next := 0; next < numTags; next++ {
+				scores[next] = negInf
+				if g.trans[curr][next] {
+					scores[next] = m.Trans[curr][next] + emissions[i+1][next] + beta[i+1][next]
+				}
+			}
+			beta[i][curr] = logSumExp(scores)
+		}
+	}
+
+	for tag := 0; tag < numTags; tag++ {
+		scores[tag] = alpha[n-1][tag] + beta[n-1][tag]
+	}
+	z := logSumExp(scores)
+	marginals := make([][]float64, n)
+	for i := range marginals {
+		marginals[i] = make([]float64, numTags)
+		for tag := range marginals[i] {
+			marginals[i][tag] = math.Exp(alpha[i][tag] + beta[i][tag] - z)
+		}
+	}
+	return marginals
+}
+
+// logSumExp returns log(sum(exp(x))) without overflow.
+func logSumExp(xs []float64) float64 {
+	best := math.Inf(-1)
+	for _, x := range xs {
+		best = math.Max(best, x)
+	}
+	if math.IsInf(best, -1) {
+		return best
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += math.Exp(x - best)
+	}
+	return best + math.Log(sum)
+}
@@ -3,6 +3,8 @@ package crf
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -18,56 +20,164 @@ const (
 	TagS = 3 // Single
 )
 
+// Pseudo tags used in the model file for start and end transitions.
+const (
+	TagBOS = "BOS"
+	TagEOS = "EOS"
+)
+
+// SegLabels is the default BMES label set used for word segmentation.
+var SegLabels = []string{"B", "M", "E", "S"}
+
 // Model represents a Linear Chain CRF model.
 type Model struct {
+	// Labels names the tags; a tag id is an index into Labels (BMES by default).
+	Labels []string
 	// Trans[from][to] = weight
-	Trans [4][4]float64
+	Trans [][]float64
+	// Start[tag] = weight of a sequence beginning with tag (BOS -> tag)
+	Start []float64
+	// End[tag] = weight of a sequence ending with tag (tag -> EOS)
+	End []float64
 	// Feats[feature_string][label_id] = weight
-	// feature_string typically "U02:Char" or similar
+	// feature_string typically "U02:Char" or similar. A quantized model keeps its feature
+	// weights packed instead and Feats is nil; Weight reads either.
 	Feats map[string]map[int]float64
+	// Scale is the quantization step of feature weights (0 = full precision).
+	// A quantized model holds feature weights as int8 or int16 multiples of Scale, in
+	// memory and in the file. Training resets it to 0.
+	Scale float64
+
+	grammar grammar
+	packed  *packedFeats // feature weights of a quantized model, see pack
 }
 
-// NewModel creates a new empty model.
+// NewModel creates a new empty BMES segmentation model.
 func NewModel() *Model {
-	return &Model{
+	return NewModelWithLabels(SegLabels)
+}
+
+// NewModelWithLabels creates a new empty model over the given labels,
+// e.g. O, B-PER, I-PER, ... for BIO entity tagging.
+func NewModelWithLabels(labels []string) *Model {
+	m := &Model{
 		Feats: make(map[string]map[int]float64),
 	}
+	m.setLabels(labels)
+	return m
+}
+
+// setLabels resets the transition tables for a new label set.
+func (m *Model) setLabels(labels []string) {
+	n := len(labels)
+	m.Labels = append([]string(nil), labels...)
+	m.Trans = make([][]float64, n)
+	for i := range m.Trans {
+		m.Trans[i] = make([]float64, n)
+	}
+	m.Start = make([]float64, n)
+	m.End = make([]float64, n)
+	m.grammar = newGrammar(m.Labels)
+}
+
+// NumTags returns the number of labels of the model.
+func (m *Model) NumTags() int {
+	return len(m.Labels)
+}
+
+// TagName returns the label of a tag id.
+func (m *Model) TagName(t int) string {
+	if t >= 0 && t < len(m.Labels) {
+		return m.Labels[t]
+	}
+	return "?"
+}
+
+// TagIndex returns the tag id of a label, or -1 if the model has no such label.
+func (m *Model) TagIndex(label string) int {
+	for i, l := range m.Labels {
+		if l == label {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Model) isSeg() bool {
+	return m.grammar.kind == grammarBMES
 }
 
 // Load loads a simple text-based CRF model.
 // Format lines:
 // T from_tag to_tag weight
 // F feature_string tag weight
+// Start and end transitions use the pseudo tags BOS and EOS,
+// e.g. "T BOS B weight" and "T E EOS weight".
+// A quantized model starts with "Q scale" and its F weights are integer codes.
+// A model over labels other than BMES starts with "L label1 label2 ...".
 func (m *Model) Load(path string) error {
 	file, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
+	return m.Read(file)
+}
 
-	scanner := bufio.NewScanner(file)
+// Read loads a model in the format of Load from r.
+func (m *Model) Read(r io.Reader) error {
+	m.unpack()
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 		parts := strings.Fields(line)
+		if len(parts) == 2 && parts[0] == "Q" {
+			if scale, err := strconv.ParseFloat(parts[1], 64); err == nil && scale > 0 {
+				m.Scale = scale
+			}
+			continue
+		}
+		if len(parts) >= 2 && parts[0] == "L" {
+			m.setLabels(parts[1:])
+			continue
+		}
 		if len(parts) < 4 {
 			continue
 		}
 
 		kind := parts[0]
 		if kind == "T" {
-			from := parseTag(parts[1])
-			to := parseTag(parts[2])
 			weight, err := strconv.ParseFloat(parts[3], 64)
-			if err == nil && from >= 0 && to >= 0 {
+			if err != nil {
+				continue
+			}
+			if parts[1] == TagBOS {
+				if to := m.TagIndex(parts[2]); to >= 0 {
+					m.Start[to] = weight
+				}
+				continue
+			}
+			if parts[2] == TagEOS {
+				if from := m.TagIndex(parts[1]); from >= 0 {
+					m.End[from] = weight
+				}
+				continue
+			}
+			from := m.TagIndex(parts[1])
+			to := m.TagIndex(parts[2])
+			if from >= 0 && to >= 0 {
 				m.Trans[from][to] = weight
 			}
 		} else if kind == "F" {
 			featStr := parts[1]
-			tag := parseTag(parts[2])
+			tag := m.TagIndex(parts[2])
 			weight, err := strconv.ParseFloat(parts[3], 64)
+			if m.Scale > 0 {
+				weight *= m.Scale
+			}
 			if err == nil && tag >= 0 {
 				if m.Feats[featStr] == nil {
 					m.Feats[featStr] = make(map[int]float64)
@@ -76,7 +186,11 @@ func (m *Model) Load(path string) error {
 			}
 		}
 	}
-	return scanner.Err()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	m.pack()
+	return nil
 }
 
 // Save saves the model to a file.
@@ -86,30 +200,61 @@ func (m *Model) Save(path string) error {
 		return err
 	}
 	defer file.Close()
-	writer := bufio.NewWriter(file)
+	return m.Write(file)
+}
+
+// Write writes the model in the text format understood by Load.
+func (m *Model) Write(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	n := m.NumTags()
+
+	if !m.isSeg() {
+		fmt.Fprintf(writer, "L %s\n", strings.Join(m.Labels, " "))
+	}
+	if m.Scale > 0 {
+		fmt.Fprintf(writer, "Q %g\n", m.Scale)
+	}
 
 	// Save Transitions
-	for i := 0; i < 4; i++ {
-		for j := 0; j < 4; j++ {
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
 			if m.Trans[i][j] != 0 {
-				fmt.Fprintf(writer, "T %s %s %f\n", TagStr(i), TagStr(j), m.Trans[i][j])
+				fmt.Fprintf(writer, "T %s %s %f\n", m.TagName(i), m.TagName(j), m.Trans[i][j])
 			}
 		}
 	}
 
+	// Save Start / End Transitions
+	for i := 0; i < n; i++ {
+		if m.Start[i] != 0 {
+			fmt.Fprintf(writer, "T %s %s %f\n", TagBOS, m.TagName(i), m.Start[i])
+		}
+		if m.End[i] != 0 {
+			fmt.Fprintf(writer, "T %s %s %f\n", m.TagName(i), TagEOS, m.End[i])
+		}
+	}
+
 	// Save Features
-	for feat, weights := range m.Feats {
+	for feat, weights := range m.features() {
 		for tag, w := range weights {
-			if w != 0 {
-				fmt.Fprintf(writer, "F %s %s %f\n", feat, TagStr(tag), w)
+			if w == 0 {
+				continue
+			}
+			if m.Scale > 0 {
+				fmt.Fprintf(writer, "F %s %s %d\n", feat, m.TagName(tag), int(math.Round(w/m.Scale)))
+			} else {
+				fmt.Fprintf(writer, "F %s %s %f\n", feat, m.TagName(tag), w)
 			}
 		}
 	}
 	return writer.Flush()
 }
 
-// UpdateFeat updates a feature weight.
+// UpdateFeat updates a feature weight. An updated weight is off the quantization grid, so
+// the model goes back to full precision (Scale 0) until it is quantized again.
 func (m *Model) UpdateFeat(feat string, tag int, delta float64) {
+	m.unpack()
+	m.Scale = 0
 	if m.Feats[feat] == nil {
 		m.Feats[feat] = make(map[int]float64)
 	}
@@ -122,34 +267,21 @@ func (m *Model) UpdateFeat(feat string, tag int, delta float64) {
 	}
 }
 
-// TagStr returns the string representation of a tag.
+// TagStr returns the string representation of a BMES tag.
 func TagStr(t int) string {
-	switch t {
-	case TagB:
-		return "B"
-	case TagM:
-		return "M"
-	case TagE:
-		return "E"
-	case TagS:
-		return "S"
+	if t >= 0 && t < len(SegLabels) {
+		return SegLabels[t]
 	}
 	return "?"
 }
 
 func parseTag(s string) int {
-	switch s {
-	case "B":
-		return TagB
-	case "M":
-		return TagM
-	case "E":
-		return TagE
-	case "S":
-		return TagS
-	default:
-		return -1
+	for i, l := range SegLabels {
+		if l == s {
+			return i
+		}
 	}
+	return -1
 }
 
 // Sentence represents a training sentence with its golden tags.
@@ -176,35 +308,40 @@ func LoadCorpus(path string) ([]Sentence, error) {
 		if line == "" {
 			continue
 		}
-		words := strings.Fields(line)
-		var runes []rune
-		var tags []int
-		for _, word := range words {
-			if util.IsPunctuation(word) {
-				continue
-			}
-			wRunes := []rune(word)
-			if len(wRunes) == 0 {
-				continue
-			}
-			runes = append(runes, wRunes...)
-			if len(wRunes) == 1 {
-				tags = append(tags, TagS)
-			} else {
-				tags = append(tags, TagB)
-				for k := 0; k < len(wRunes)-2; k++ {
-					tags = append(tags, TagM)
-				}
-				tags = append(tags, TagE)
-			}
-		}
-		if len(runes) > 0 {
-			data = append(data, Sentence{runes, tags})
+		sent := SentenceFromWords(strings.Fields(line))
+		if len(sent.Runes) > 0 {
+			data = append(data, sent)
 		}
 	}
 	return data, scanner.Err()
 }
 
+// SentenceFromWords builds a training sentence from a segmented word list, skipping punctuation.
+func SentenceFromWords(words []string) Sentence {
+	var runes []rune
+	var tags []int
+	for _, word := range words {
+		if util.IsPunctuation(word) {
+			continue
+		}
+		wRunes := []rune(word)
+		if len(wRunes) == 0 {
+			continue
+		}
+		runes = append(runes, wRunes...)
+		if len(wRunes) == 1 {
+			tags = append(tags, TagS)
+		} else {
+			tags = append(tags, TagB)
+			for k := 0; k < len(wRunes)-2; k++ {
+				tags = append(tags, TagM)
+			}
+			tags = append(tags, TagE)
+		}
+	}
+	return Sentence{runes, tags}
+}
+
 // LoadDictAsCorpus loads a dictionary and converts each word into a training sentence.
 func LoadDictAsCorpus(path string) ([]Sentence, error) {
 	file, err := os.Open(path)
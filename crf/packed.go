@@ -0,0 +1,109 @@
+package crf
+
+import "math"
+
+// packedFeats holds the feature weights of a quantized model as integer codes, a few bytes
+// per weight instead of a map per feature. The weights of a feature are the entries
+// [start, start+n) of tags and of codes8 or codes16; a weight is its code times Scale.
+type packedFeats struct {
+	spans   map[string]packedSpan
+	tags    []uint8
+	codes8  []int8  // codes of up to 8 bits
+	codes16 []int16 // codes of 9 to 16 bits
+}
+
+type packedSpan struct {
+	start uint32
+	n     uint8
+}
+
+func (p *packedFeats) code(i int) int {
+	if p.codes8 != nil {
+		return int(p.codes8[i])
+	}
+	return int(p.codes16[i])
+}
+
+// pack moves the feature weights of a quantized model from Feats into integer codes. Models
+// with codes wider than 16 bits or more than 255 labels keep their weights in Feats.
+func (m *Model) pack() {
+	if m.Scale <= 0 || m.packed != nil || m.NumTags() > math.MaxUint8 {
+		return
+	}
+	total, maxCode := 0, 0
+	for _, weights := range m.Feats {
+		total += len(weights)
+		for _, w := range weights {
+			maxCode = max(maxCode, int(math.Abs(math.Round(w/m.Scale))))
+		}
+	}
+	if maxCode > math.MaxInt16 {
+		return
+	}
+
+	p := &packedFeats{spans: make(map[string]packedSpan, len(m.Feats)), tags: make([]uint8, 0, total)}
+	if maxCode <= math.MaxInt8 {
+		p.codes8 = make([]int8, 0, total)
+	} else {
+		p.codes16 = make([]int16, 0, total)
+	}
+	for feat, weights := range m.Feats {
+		p.spans[feat] = packedSpan{start: uint32(len(p.tags)), n: uint8(len(weights))}
+		for tag, w := range weights {
+			code := math.Round(w / m.Scale)
+			p.tags = append(p.tags, uint8(tag))
+			if p.codes8 != nil {
+				p.codes8 = append(p.codes8, int8(code))
+			} else {
+				p.codes16 = append(p.codes16, int16(code))
+			}
+		}
+	}
+	m.packed = p
+	m.Feats = nil
+}
+
+// unpack returns the weights of a packed model to Feats before they are changed.
+func (m *Model) unpack() {
+	if m.packed == nil {
+		return
+	}
+	m.Feats = m.features()
+	m.packed = nil
+}
+
+// features returns the feature weights by feature: Feats, or a map unpacked from the codes
+// of a quantized model.
+func (m *Model) features() map[string]map[int]float64 {
+	p := m.packed
+	if p == nil {
+		return m.Feats
+	}
+	feats := make(map[string]map[int]float64, len(p.spans))
+	for feat, span := range p.spans {
+		weights := make(map[int]float64, span.n)
+		for i := int(span.start); i < int(span.start)+int(span.n); i++ {
+			weights[int(p.tags[i])] = float64(p.code(i)) * m.Scale
+		}
+		feats[feat] = weights
+	}
+	return feats
+}
+
+// Weight returns the weight of a feature for a tag, 0 when the model has none.
+func (m *Model) Weight(feat string, tag int) float64 {
+	p := m.packed
+	if p == nil {
+		return m.Feats[feat][tag]
+	}
+	span, ok := p.spans[feat]
+	if !ok {
+		return 0
+	}
+	for i := int(span.start); i < int(span.start)+int(span.n); i++ {
+		if int(p.tags[i]) == tag {
+			return float64(p.code(i)) * m.Scale
+		}
+	}
+	return 0
+}
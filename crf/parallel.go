@@ -0,0 +1,74 @@
+package crf
+
+import "sync"
+
+// add merges another difference vector into d.
+func (d *delta) add(o *delta) {
+	for f, w := range o.feats {
+		dw := d.feat(f)
+		for tag, v := range w {
+			dw[tag] += v
+		}
+	}
+	for i := range d.trans {
+		for j := range d.trans[i] {
+			d.trans[i][j] += o.trans[i][j]
+		}
+		d.start[i] += o.start[i]
+		d.end[i] += o.end[i]
+	}
+}
+
+// TrainBatch runs one mini-batch perceptron step: the batch is split into one shard per thread,
+// every shard is decoded in parallel against the current weights, and the summed updates are
+// applied afterwards in shard order. The result therefore only depends on the batch order and
+// the number of threads. It returns the number of mispredicted sentences.
+func (m *Model) TrainBatch(sents []Sentence, threads int, rate float64) int {
+	if threads < 1 {
+		threads = 1
+	}
+	if threads > len(sents) {
+		threads = len(sents)
+	}
+	if threads == 0 {
+		return 0
+	}
+
+	shardSize := (len(sents) + threads - 1) / threads
+	deltas := make([]*delta, threads)
+	errors := make([]int, threads)
+
+	var wg sync.WaitGroup
+	for t := 0; t < threads; t++ {
+		lo := t * shardSize
+		if lo >= len(sents) {
+			break
+		}
+		hi := min(lo+shardSize, len(sents))
+
+		wg.Add(1)
+		go func(t int, shard []Sentence) {
+			defer wg.Done()
+			d := newEmptyDelta(m.NumTags())
+			for _, sent := range shard {
+				pred := m.Decode(sent.Runes)
+				if len(pred) != len(sent.Tags) || equalTags(pred, sent.Tags) {
+					continue
+				}
+				d.add(newDelta(m.NumTags(), sent.Runes, sent.Tags, pred))
+				errors[t]++
+			}
+			deltas[t] = d
+		}(t, sents[lo:hi])
+	}
+	wg.Wait()
+
+	total := 0
+	for t, d := range deltas {
+		if d != nil {
+			d.apply(m, rate)
+		}
+		total += errors[t]
+	}
+	return total
+}
@@ -2,16 +2,26 @@ package dictionary
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"math"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // Dictionary holds words and their frequencies/probabilities.
 type Dictionary struct {
-	Total  float64
-	Words  map[string]float64
+	Total float64
+	Words map[string]float64
+	// Tags holds the optional part-of-speech tag of a word (e.g. "n", "ns").
+	Tags map[string]string
+	// Synonyms holds the aliases of a word (7天 -> 七天), see LoadSynonyms.
+	Synonyms map[string][]string
+	// Layers holds the layer a word was last loaded from, e.g. "core" for dict_core.txt.
+	Layers map[string]string
 	MaxLen int
 	Loaded bool
 }
@@ -19,20 +29,27 @@ type Dictionary struct {
 // NewDictionary creates a new empty dictionary.
 func NewDictionary() *Dictionary {
 	return &Dictionary{
-		Words: make(map[string]float64),
+		Words:    make(map[string]float64),
+		Tags:     make(map[string]string),
+		Synonyms: make(map[string][]string),
+		Layers:   make(map[string]string),
 	}
 }
 
 // Load loads words from a file.
-// File format: word frequency (space separated)
+// File format: word frequency [tag] (space separated)
 func (d *Dictionary) Load(path string) error {
 	file, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
+	return d.Read(file, LayerName(path))
+}
 
-	scanner := bufio.NewScanner(file)
+// Read loads words in the Load format from r and records them under the given layer.
+func (d *Dictionary) Read(r io.Reader, layer string) error {
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
@@ -41,31 +58,138 @@ func (d *Dictionary) Load(path string) error {
 		parts := strings.Fields(line)
 		word := parts[0]
 		freq := 1.0 // default
+		tag := ""
 		if len(parts) >= 2 {
 			f, err := strconv.ParseFloat(parts[1], 64)
 			if err == nil {
 				freq = f
 			}
+			if len(parts) >= 3 {
+				tag = parts[2]
+			}
 		} else {
 			// If it's a top-level word without frequency, give it a high default
 			freq = 20000.0
 		}
-		d.Words[word] = freq
-		d.Total += freq
-		if len([]rune(word)) > d.MaxLen {
-			d.MaxLen = len([]rune(word))
+		d.Add(word, freq, tag)
+		d.setLayer(word, layer)
+	}
+	d.Loaded = true
+	return scanner.Err()
+}
+
+// LoadJieba loads a jieba-format dictionary (dict.txt or a user dict).
+// Line format: word [frequency] [tag], where both frequency and tag are optional.
+func (d *Dictionary) LoadJieba(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	layer := LayerName(path)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		parts := strings.Fields(line)
+		word := parts[0]
+		freq := 20000.0 // same default as a word without frequency in Load
+		tag := ""
+		for _, p := range parts[1:] {
+			if f, err := strconv.ParseFloat(p, 64); err == nil {
+				freq = f
+			} else {
+				tag = p
+			}
 		}
+		d.Add(word, freq, tag)
+		d.setLayer(word, layer)
 	}
 	d.Loaded = true
 	return scanner.Err()
 }
 
+// Add inserts or overrides a word. An empty tag keeps any previously known tag.
+func (d *Dictionary) Add(word string, freq float64, tag string) {
+	d.Words[word] = freq
+	d.Total += freq
+	if tag != "" {
+		if d.Tags == nil {
+			d.Tags = make(map[string]string)
+		}
+		d.Tags[word] = tag
+	}
+	if len([]rune(word)) > d.MaxLen {
+		d.MaxLen = len([]rune(word))
+	}
+}
+
+// Save writes the dictionary in the "word frequency [tag]" format understood by Load,
+// ordered by descending frequency.
+func (d *Dictionary) Save(path string) error {
+	words := make([]string, 0, len(d.Words))
+	for w := range d.Words {
+		words = append(words, w)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if d.Words[words[i]] != d.Words[words[j]] {
+			return d.Words[words[i]] > d.Words[words[j]]
+		}
+		return words[i] < words[j]
+	})
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+
+	for _, w := range words {
+		freq := strconv.FormatFloat(d.Words[w], 'f', -1, 64)
+		if tag := d.Tags[w]; tag != "" {
+			fmt.Fprintf(writer, "%s %s %s\n", w, freq, tag)
+		} else {
+			fmt.Fprintf(writer, "%s %s\n", w, freq)
+		}
+	}
+	return writer.Flush()
+}
+
 // Frequency returns the frequency of a word.
 func (d *Dictionary) Frequency(word string) (float64, bool) {
 	val, ok := d.Words[word]
 	return val, ok
 }
 
+// Tag returns the part-of-speech tag of a word, if known.
+func (d *Dictionary) Tag(word string) (string, bool) {
+	tag, ok := d.Tags[word]
+	return tag, ok
+}
+
+// Layer returns the layer a word was last loaded from ("core", "base", "user"), or ""
+// for words added in code.
+func (d *Dictionary) Layer(word string) string {
+	return d.Layers[word]
+}
+
+// LayerName names the layer of a dictionary file: data/dict_user.txt -> user.
+func LayerName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return strings.TrimPrefix(name, "dict_")
+}
+
+func (d *Dictionary) setLayer(word, layer string) {
+	if d.Layers == nil {
+		d.Layers = make(map[string]string)
+	}
+	d.Layers[word] = layer
+}
+
 // Contains checks if a word exists in the dictionary.
 func (d *Dictionary) Contains(word string) bool {
 	_, ok := d.Words[word]
@@ -2,6 +2,7 @@ package dictionary
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -37,6 +38,16 @@ func TestDictionary_Load(t *testing.T) {
 	if !dict.Contains("南京市") {
 		t.Errorf("dict should contain '南京市'")
 	}
+
+	if layer := dict.Layer("南京市"); layer != LayerName(tmpfile.Name()) {
+		t.Errorf("Layer('南京市') = %q, want %q", layer, LayerName(tmpfile.Name()))
+	}
+}
+
+func TestLayerName(t *testing.T) {
+	if got := LayerName("data/dict_user.txt"); got != "user" {
+		t.Errorf("LayerName() = %q, want user", got)
+	}
 }
 
 func TestDictionary_LogProbability(t *testing.T) {
@@ -56,3 +67,66 @@ func TestDictionary_LogProbability(t *testing.T) {
 		t.Errorf("LogProbability('Unknown') = %v, want -20.0", probUnknown)
 	}
 }
+
+func TestDictionary_LoadJieba(t *testing.T) {
+	content := "云计算 5\n凱特琳 nz\n创新办 3 i\n"
+	path := filepath.Join(t.TempDir(), "dict.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dict := NewDictionary()
+	if err := dict.LoadJieba(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, _ := dict.Frequency("云计算"); f != 5 {
+		t.Errorf("Frequency('云计算') = %v, want 5", f)
+	}
+	if f, _ := dict.Frequency("凱特琳"); f != 20000 {
+		t.Errorf("Frequency('凱特琳') = %v, want 20000", f)
+	}
+	if tag, _ := dict.Tag("创新办"); tag != "i" {
+		t.Errorf("Tag('创新办') = %q, want 'i'", tag)
+	}
+
+	// Saving and loading back through the native format keeps words and tags.
+	out := filepath.Join(t.TempDir(), "dict_core.txt")
+	if err := dict.Save(out); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewDictionary()
+	if err := loaded.Load(out); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Total != dict.Total {
+		t.Errorf("loaded.Total = %v, want %v", loaded.Total, dict.Total)
+	}
+	if tag, _ := loaded.Tag("凱特琳"); tag != "nz" {
+		t.Errorf("Tag('凱特琳') = %q, want 'nz'", tag)
+	}
+}
+
+func TestLoadSynonyms(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dict_synonyms.txt")
+	os.WriteFile(path, []byte("# 别名\n希尔顿欢朋 欢朋 Hampton\n7天 七天\n"), 0644)
+
+	dict := NewDictionary()
+	if err := dict.LoadSynonyms(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := dict.SynonymsOf("欢朋"); len(got) != 2 || got[0] != "希尔顿欢朋" || got[1] != "Hampton" {
+		t.Errorf("SynonymsOf('欢朋') = %v", got)
+	}
+	if got := dict.SynonymsOf("七天"); len(got) != 1 || got[0] != "7天" {
+		t.Errorf("SynonymsOf('七天') = %v", got)
+	}
+	if dict.Contains("七天") {
+		t.Error("synonyms must not add words to the dictionary")
+	}
+
+	os.WriteFile(path, []byte("孤词\n"), 0644)
+	if err := dict.LoadSynonyms(path); err == nil {
+		t.Error("expected an error for a group of one word")
+	}
+}
@@ -0,0 +1,59 @@
+package dictionary
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// AddSynonyms makes the words aliases of each other, e.g. AddSynonyms("7天", "七天", "7天酒店").
+func (d *Dictionary) AddSynonyms(words ...string) {
+	if d.Synonyms == nil {
+		d.Synonyms = make(map[string][]string)
+	}
+	for _, w := range words {
+		for _, s := range words {
+			if s != w && !slices.Contains(d.Synonyms[w], s) {
+				d.Synonyms[w] = append(d.Synonyms[w], s)
+			}
+		}
+	}
+}
+
+// SynonymsOf returns the aliases of a word.
+func (d *Dictionary) SynonymsOf(word string) []string {
+	return d.Synonyms[word]
+}
+
+// LoadSynonyms loads a synonym layer with one group of aliases per line, space separated:
+//
+//	希尔顿欢朋 欢朋 Hampton
+//	7天 七天
+//
+// Groups sharing a word are not merged; each line only links its own words. Lines starting
+// with # are comments. The words are not added to the dictionary; add them to a dict_*.txt
+// layer if the segmenter should cut them as words.
+func (d *Dictionary) LoadSynonyms(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 {
+			return fmt.Errorf("%s:%d: expected at least two synonyms", path, lineNo)
+		}
+		d.AddSynonyms(fields...)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,150 @@
+// Package fingerprint computes SimHash and MinHash signatures of documents over the
+// segmenter's words, to find near-identical texts such as the same listing posted twice
+// with a different price or punctuation.
+package fingerprint
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/segmenter"
+	"github.com/teatak/seg/util"
+)
+
+// Feature is a word of a document with its weight.
+type Feature struct {
+	Word   string
+	Weight float64
+}
+
+// FrequencyWeight weighs a word by its information in the dictionary, -log(freq/total),
+// so that rare words such as brand names count more than 的 or 酒店. Unknown words get
+// the median weight, like unknown words of an IDF table.
+func FrequencyWeight(dict *dictionary.Dictionary) func(word string) float64 {
+	weights := make([]float64, 0, len(dict.Words))
+	for w := range dict.Words {
+		weights = append(weights, -dict.LogProbability(w))
+	}
+	median := 1.0
+	if len(weights) > 0 {
+		sort.Float64s(weights)
+		median = weights[len(weights)/2]
+	}
+	return func(word string) float64 {
+		if !dict.Contains(word) {
+			return median
+		}
+		return -dict.LogProbability(word)
+	}
+}
+
+// Fingerprinter turns texts into features and signatures.
+type Fingerprinter struct {
+	Seg       *segmenter.Segmenter
+	Weight    func(word string) float64 // nil weighs every word 1
+	Mode      segmenter.Mode
+	Shingle   int // MinHash shingle size in words; 1 compares bags of words
+	NumHashes int // MinHash signature length
+}
+
+// New creates a fingerprinter over seg; weight is typically FrequencyWeight(dict) or the
+// Get method of a keywords.IDF table.
+func New(seg *segmenter.Segmenter, weight func(word string) float64) *Fingerprinter {
+	return &Fingerprinter{Seg: seg, Weight: weight, Mode: segmenter.ModeHybrid, Shingle: 2, NumHashes: 128}
+}
+
+// Words returns the words of text without punctuation and whitespace.
+func (f *Fingerprinter) Words(text string) []string {
+	var words []string
+	for _, t := range f.Seg.RawTokens(text, f.Mode) {
+		if w := strings.TrimSpace(t.Text); w != "" && !util.IsPunctuation(w) {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// Features returns the distinct words of text in order of first occurrence; a word
+// occurring several times weighs the sum of its occurrences.
+func (f *Fingerprinter) Features(text string) []Feature {
+	return f.WordFeatures(f.Words(text))
+}
+
+// WordFeatures is Features for words already cut with Words.
+func (f *Fingerprinter) WordFeatures(words []string) []Feature {
+	var features []Feature
+	index := make(map[string]int)
+	for _, w := range words {
+		weight := 1.0
+		if f.Weight != nil {
+			weight = f.Weight(w)
+		}
+		if i, ok := index[w]; ok {
+			features[i].Weight += weight
+			continue
+		}
+		index[w] = len(features)
+		features = append(features, Feature{Word: w, Weight: weight})
+	}
+	return features
+}
+
+// SimHash returns the SimHash of the weighted words of text. A text without words (only
+// punctuation or emoji) hashes to 0 like every other such text; skip it when deduplicating.
+func (f *Fingerprinter) SimHash(text string) uint64 {
+	return SimHash(f.Features(text))
+}
+
+// MinHash returns the MinHash signature of the word shingles of text. A text without words
+// gets the same signature as every other such text; skip it when deduplicating.
+func (f *Fingerprinter) MinHash(text string) Signature {
+	return MinHash(Shingles(f.Words(text), f.Shingle), f.NumHashes)
+}
+
+// SimHash combines the 64-bit hashes of the features: bit i is set when the features with
+// bit i set outweigh those without. Similar documents get hashes at a small Hamming distance.
+func SimHash(features []Feature) uint64 {
+	var v [64]float64
+	for _, ft := range features {
+		h := hashString(ft.Word)
+		for i := range v {
+			if h&(1<<i) != 0 {
+				v[i] += ft.Weight
+			} else {
+				v[i] -= ft.Weight
+			}
+		}
+	}
+	var hash uint64
+	for i, x := range v {
+		if x > 0 {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
+
+// HammingDistance returns the number of differing bits of a and b.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// hashString hashes s with FNV-1a, mixed so that every output bit depends on every input bit.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return mix64(h.Sum64())
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
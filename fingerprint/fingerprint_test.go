@@ -0,0 +1,89 @@
+package fingerprint
+
+import (
+	"math"
+	"testing"
+
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/segmenter"
+)
+
+func newFingerprinter() *Fingerprinter {
+	dict := dictionary.NewDictionary()
+	for _, w := range []string{"希尔顿", "欢朋", "酒店", "位于", "北京市", "朝阳区", "毗邻", "三里屯", "免费", "早餐", "海景", "大床房", "如家", "快捷", "上海", "浦东"} {
+		dict.Add(w, 100, "")
+	}
+	dict.Add("酒店", 10000, "")
+	return New(segmenter.NewSegmenter(dict), FrequencyWeight(dict))
+}
+
+func TestSimHash(t *testing.T) {
+	f := newFingerprinter()
+	a := f.SimHash("希尔顿欢朋酒店位于北京市朝阳区，毗邻三里屯，免费早餐。")
+	b := f.SimHash("希尔顿欢朋酒店位于北京市朝阳区，毗邻三里屯！免费早餐")
+	c := f.SimHash("如家快捷酒店位于上海浦东，海景大床房")
+	if d := HammingDistance(a, b); d != 0 {
+		t.Errorf("punctuation changed the hash: distance %d", d)
+	}
+	if d := HammingDistance(a, c); d <= 3 {
+		t.Errorf("different listings too close: distance %d", d)
+	}
+
+	x := NewSimHashIndex(3)
+	x.Add(a)
+	x.Add(c)
+	if id, ok := x.Find(a ^ 0b101); !ok || id != 0 {
+		t.Errorf("Find(2 bits off) = %d, %v", id, ok)
+	}
+	if _, ok := x.Find(^a); ok {
+		t.Error("Find(complement) found a match")
+	}
+}
+
+func TestMinHash(t *testing.T) {
+	f := newFingerprinter()
+	f.Shingle = 1
+	a := f.MinHash("希尔顿欢朋酒店位于北京市朝阳区，毗邻三里屯，免费早餐")
+	b := f.MinHash("希尔顿欢朋酒店位于北京市朝阳区，毗邻三里屯，海景大床房")
+	c := f.MinHash("如家快捷酒店位于上海浦东")
+
+	exact := Jaccard(f.Words("希尔顿欢朋酒店位于北京市朝阳区，毗邻三里屯，免费早餐"), f.Words("希尔顿欢朋酒店位于北京市朝阳区，毗邻三里屯，海景大床房"))
+	if exact != 8.0/12 {
+		t.Errorf("Jaccard() = %v, want 8/12", exact)
+	}
+	if est := a.Similarity(b); math.Abs(est-exact) > 0.15 {
+		t.Errorf("Similarity() = %v, want about %v", est, exact)
+	}
+
+	x := NewMinHashIndex(32, 0.5)
+	x.Add(c)
+	x.Add(a)
+	if id, ok := x.Find(b); !ok || id != 1 {
+		t.Errorf("Find() = %d, %v, want 1", id, ok)
+	}
+	if _, ok := NewMinHashIndex(32, 0.9).Find(b); ok {
+		t.Error("empty index found a match")
+	}
+
+	if err := ValidBands(128, 16); err != nil {
+		t.Errorf("ValidBands(128, 16) = %v", err)
+	}
+	for _, bands := range []int{0, 20, 256} {
+		if ValidBands(128, bands) == nil {
+			t.Errorf("ValidBands(128, %d) accepted", bands)
+		}
+	}
+	if words := f.Words("！！？…"); len(words) != 0 {
+		t.Errorf("Words(punctuation) = %q, want none", words)
+	}
+}
+
+func TestShingles(t *testing.T) {
+	words := []string{"a", "b", "c"}
+	if got := Shingles(words, 2); len(got) != 2 || got[0] != "a b" || got[1] != "b c" {
+		t.Errorf("Shingles(2) = %q", got)
+	}
+	if got := Shingles(words, 5); len(got) != 1 || got[0] != "a b c" {
+		t.Errorf("Shingles(5) = %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+package fingerprint
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// SimHashIndex finds stored hashes within MaxDistance bits of a query. The hash is split
+// into MaxDistance+1 blocks: two hashes that close agree on at least one whole block, so
+// only the hashes sharing a block are compared.
+type SimHashIndex struct {
+	MaxDistance int
+	hashes      []uint64
+	blocks      []map[uint64][]int // per block: block value -> ids
+	shifts      []uint
+	masks       []uint64
+}
+
+// NewSimHashIndex creates an index for distances up to maxDistance (3 is usual for 64 bits).
+func NewSimHashIndex(maxDistance int) *SimHashIndex {
+	n := maxDistance + 1
+	x := &SimHashIndex{MaxDistance: maxDistance}
+	shift := uint(0)
+	for i := 0; i < n; i++ {
+		width := uint(64 / n)
+		if i == n-1 {
+			width = 64 - shift
+		}
+		x.blocks = append(x.blocks, make(map[uint64][]int))
+		x.shifts = append(x.shifts, shift)
+		x.masks = append(x.masks, 1<<width-1)
+		shift += width
+	}
+	return x
+}
+
+// Add stores hash and returns its id, the number of hashes added before it.
+func (x *SimHashIndex) Add(hash uint64) int {
+	id := len(x.hashes)
+	x.hashes = append(x.hashes, hash)
+	for i, block := range x.blocks {
+		key := hash >> x.shifts[i] & x.masks[i]
+		block[key] = append(block[key], id)
+	}
+	return id
+}
+
+// Find returns the id of the closest stored hash within MaxDistance bits.
+func (x *SimHashIndex) Find(hash uint64) (int, bool) {
+	best, bestDistance := -1, x.MaxDistance+1
+	for i, block := range x.blocks {
+		for _, id := range block[hash>>x.shifts[i]&x.masks[i]] {
+			if d := HammingDistance(hash, x.hashes[id]); d < bestDistance || d == bestDistance && id < best {
+				best, bestDistance = id, d
+			}
+		}
+	}
+	return best, best >= 0
+}
+
+// MinHashIndex finds stored signatures with an estimated Jaccard similarity of at least
+// Threshold, using locality-sensitive hashing: signatures are cut into bands and only those
+// with an identical band are compared.
+type MinHashIndex struct {
+	Threshold float64
+	bands     int
+	sigs      []Signature
+	buckets   []map[uint64][]int // per band: band hash -> ids
+}
+
+// NewMinHashIndex creates an index with the given number of bands; more bands find pairs
+// of lower similarity at the cost of more comparisons. The signature length should be a
+// multiple of bands, see ValidBands.
+func NewMinHashIndex(bands int, threshold float64) *MinHashIndex {
+	x := &MinHashIndex{Threshold: threshold, bands: max(bands, 1)}
+	for i := 0; i < x.bands; i++ {
+		x.buckets = append(x.buckets, make(map[uint64][]int))
+	}
+	return x
+}
+
+// Add stores sig and returns its id, the number of signatures added before it.
+func (x *MinHashIndex) Add(sig Signature) int {
+	id := len(x.sigs)
+	x.sigs = append(x.sigs, sig)
+	for i, bucket := range x.buckets {
+		key := x.bandHash(sig, i)
+		bucket[key] = append(bucket[key], id)
+	}
+	return id
+}
+
+// Find returns the id of the most similar stored signature above Threshold.
+func (x *MinHashIndex) Find(sig Signature) (int, bool) {
+	best, bestSim := -1, 0.0
+	for i, bucket := range x.buckets {
+		for _, id := range bucket[x.bandHash(sig, i)] {
+			if s := sig.Similarity(x.sigs[id]); s >= x.Threshold && (s > bestSim || s == bestSim && id < best) {
+				best, bestSim = id, s
+			}
+		}
+	}
+	return best, best >= 0
+}
+
+// ValidBands checks that signatures of numHashes rows split evenly into bands.
+func ValidBands(numHashes, bands int) error {
+	if bands < 1 || numHashes < bands || numHashes%bands != 0 {
+		return fmt.Errorf("%d hashes do not split into %d bands of equal size", numHashes, bands)
+	}
+	return nil
+}
+
+// bandHash hashes the rows of band i of sig.
+func (x *MinHashIndex) bandHash(sig Signature, i int) uint64 {
+	rows := len(sig) / x.bands
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, v := range sig[i*rows : (i+1)*rows] {
+		for j := range buf {
+			buf[j] = byte(v >> (8 * j))
+		}
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
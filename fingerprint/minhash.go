@@ -0,0 +1,94 @@
+package fingerprint
+
+import (
+	"math"
+	"strings"
+)
+
+// Signature is a MinHash signature: the minimum of each hash function over a set.
+type Signature []uint64
+
+// MinHash returns the signature of the set of items under numHashes hash functions.
+// The fraction of equal positions in two signatures estimates the Jaccard similarity
+// of the sets.
+func MinHash(items []string, numHashes int) Signature {
+	sig := make(Signature, numHashes)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for _, item := range items {
+		h := hashString(item)
+		for i := range sig {
+			if v := mix64(h ^ seed(i)); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// seed derives the i-th hash function from the golden ratio sequence.
+func seed(i int) uint64 {
+	return mix64(uint64(i+1) * 0x9e3779b97f4a7c15)
+}
+
+// Similarity estimates the Jaccard similarity of the sets behind two signatures of the
+// same length.
+func (s Signature) Similarity(o Signature) float64 {
+	if len(s) == 0 || len(s) != len(o) {
+		return 0
+	}
+	equal := 0
+	for i := range s {
+		if s[i] == o[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(s))
+}
+
+// Shingles returns the runs of n consecutive words joined by a space; with fewer than n
+// words the whole text is one shingle.
+func Shingles(words []string, n int) []string {
+	if n <= 1 {
+		return words
+	}
+	if len(words) <= n {
+		if len(words) == 0 {
+			return nil
+		}
+		return []string{strings.Join(words, " ")}
+	}
+	shingles := make([]string, 0, len(words)-n+1)
+	for i := 0; i+n <= len(words); i++ {
+		shingles = append(shingles, strings.Join(words[i:i+n], " "))
+	}
+	return shingles
+}
+
+// Jaccard returns the exact Jaccard similarity |a ∩ b| / |a ∪ b| of two word sets;
+// two empty sets are identical.
+func Jaccard(a, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, w := range a {
+		set[w] = true
+	}
+	union := len(set)
+	inter := 0
+	seen := make(map[string]bool, len(b))
+	for _, w := range b {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		if set[w] {
+			inter++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 1
+	}
+	return float64(inter) / float64(union)
+}
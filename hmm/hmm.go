@@ -0,0 +1,136 @@
+// Package hmm is a hidden Markov model over the BMES tags of word segmentation. It is
+// trained by counting a segmented corpus and cuts the runs of characters the dictionary
+// does not cover, like jieba's HMM mode, without the cost of training a CRF model.
+package hmm
+
+import (
+	"math"
+
+	"github.com/teatak/seg/crf"
+)
+
+const numTags = 4 // B, M, E, S as in package crf
+
+// Model holds the log-probabilities of the first tag, of tag transitions and of a character
+// given its tag. Characters never seen with a tag get Unknown[tag].
+type Model struct {
+	Start   [numTags]float64
+	Trans   [numTags][numTags]float64
+	Emit    [numTags]map[rune]float64
+	Unknown [numTags]float64
+}
+
+// Train estimates a model from tagged sentences with add-one smoothing.
+func Train(sents []crf.Sentence) *Model {
+	var start [numTags]float64
+	var trans [numTags][numTags]float64
+	var emit [numTags]map[rune]float64
+	var tagTotal [numTags]float64
+	vocab := make(map[rune]bool)
+	for t := range emit {
+		emit[t] = make(map[rune]float64)
+	}
+	for _, sent := range sents {
+		for i, tag := range sent.Tags {
+			if i == 0 {
+				start[tag]++
+			} else {
+				trans[sent.Tags[i-1]][tag]++
+			}
+			emit[tag][sent.Runes[i]]++
+			tagTotal[tag]++
+			vocab[sent.Runes[i]] = true
+		}
+	}
+
+	m := &Model{}
+	startTotal := 0.0
+	for t := 0; t < numTags; t++ {
+		if crf.ValidStart(t) {
+			startTotal += start[t] + 1
+		}
+	}
+	for from := 0; from < numTags; from++ {
+		m.Start[from] = math.Inf(-1)
+		if crf.ValidStart(from) {
+			m.Start[from] = math.Log((start[from] + 1) / startTotal)
+		}
+		transTotal := 0.0
+		for to := 0; to < numTags; to++ {
+			if crf.ValidTransition(from, to) {
+				transTotal += trans[from][to] + 1
+			}
+		}
+		for to := 0; to < numTags; to++ {
+			m.Trans[from][to] = math.Inf(-1)
+			if crf.ValidTransition(from, to) {
+				m.Trans[from][to] = math.Log((trans[from][to] + 1) / transTotal)
+			}
+		}
+	}
+	v := float64(len(vocab) + 1)
+	for t := 0; t < numTags; t++ {
+		m.Emit[t] = make(map[rune]float64, len(emit[t]))
+		for r, c := range emit[t] {
+			m.Emit[t][r] = math.Log((c + 1) / (tagTotal[t] + v))
+		}
+		m.Unknown[t] = math.Log(1 / (tagTotal[t] + v))
+	}
+	return m
+}
+
+// TrainFile trains a model on a segmented corpus with one space separated sentence per line,
+// the format of data/corpus.txt.
+func TrainFile(path string) (*Model, error) {
+	sents, err := crf.LoadCorpus(path)
+	if err != nil {
+		return nil, err
+	}
+	return Train(sents), nil
+}
+
+// Decode returns the most likely BMES tags of runes (Viterbi).
+func (m *Model) Decode(runes []rune) []int {
+	n := len(runes)
+	if n == 0 {
+		return []int{}
+	}
+	negInf := math.Inf(-1)
+	dp := make([][numTags]float64, n)
+	path := make([][numTags]int, n)
+	for t := 0; t < numTags; t++ {
+		dp[0][t] = m.Start[t] + m.emission(t, runes[0])
+	}
+	for i := 1; i < n; i++ {
+		for curr := 0; curr < numTags; curr++ {
+			dp[i][curr] = negInf
+			for prev := 0; prev < numTags; prev++ {
+				if score := dp[i-1][prev] + m.Trans[prev][curr]; score > dp[i][curr] {
+					dp[i][curr] = score
+					path[i][curr] = prev
+				}
+			}
+			dp[i][curr] += m.emission(curr, runes[i])
+		}
+	}
+
+	best := crf.TagS
+	for _, t := range []int{crf.TagE, crf.TagS} {
+		if dp[n-1][t] > dp[n-1][best] {
+			best = t
+		}
+	}
+	tags := make([]int, n)
+	tags[n-1] = best
+	for i := n - 1; i > 0; i-- {
+		tags[i-1] = path[i][tags[i]]
+	}
+	return tags
+}
+
+func (m *Model) emission(tag int, r rune) float64 {
+	if p, ok := m.Emit[tag][r]; ok {
+		return p
+	}
+	return m.Unknown[tag]
+}
@@ -0,0 +1,32 @@
+package hmm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/teatak/seg/crf"
+)
+
+func TestTrainDecode(t *testing.T) {
+	var sents []crf.Sentence
+	for _, line := range [][]string{
+		{"如家", "酒店", "北京", "店"},
+		{"汉庭", "酒店", "上海", "店"},
+		{"如家", "快捷", "酒店"},
+		{"全季", "酒店", "北京", "南站", "店"},
+	} {
+		sents = append(sents, crf.SentenceFromWords(line))
+	}
+	m := Train(sents)
+
+	got := m.Decode([]rune("汉庭酒店北京店"))
+	want := []int{crf.TagB, crf.TagE, crf.TagB, crf.TagE, crf.TagB, crf.TagE, crf.TagS}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %v, want %v", got, want)
+	}
+
+	// A single character can only be tagged S, whatever it is.
+	if got := m.Decode([]rune("龘")); !reflect.DeepEqual(got, []int{crf.TagS}) {
+		t.Errorf("Decode(unknown) = %v, want [S]", got)
+	}
+}
@@ -0,0 +1,463 @@
+// Package hotel parses hotel listing names such as "7天优品（邢台隆尧城关店）" into brand,
+// sub-brand, tier, store type, location and branch components.
+package hotel
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/teatak/seg/address"
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/segmenter"
+)
+
+// DefaultBrands are the hotel brands known without any dictionary.
+var DefaultBrands = []string{
+	"希尔顿", "欢朋", "维也纳", "维也纳国际", "维也纳智好", "维也纳三好", "全季", "如家", "如家精选",
+	"汉庭", "锦江", "锦江之星", "锦江都城", "麗枫", "丽枫", "喆啡", "凯悦", "香格里拉", "喜来登",
+	"万豪", "洲际", "皇冠假日", "华美达", "智选假日", "宜必思", "莫泰", "格林豪泰", "7天", "IU",
+	"潮漫", "希岸", "扉蔓", "丽怡", "白玉兰", "康铂", "凯里亚德", "郁锦香", "首旅如家", "和颐", "满兮",
+}
+
+// knownSubBrands lists sub-brands that are written together with their parent brand,
+// longest parent first.
+var knownSubBrands = []struct {
+	brand string
+	subs  []string
+}{
+	{"希尔顿", []string{"欢朋", "花园", "逸林", "格芮", "嘉悦里"}},
+	{"维也纳", []string{"国际", "智好", "三好", "好眠"}},
+	{"如家", []string{"精选", "商旅"}},
+	{"7天", []string{"优品"}},
+	{"希岸", []string{"轻雅"}},
+	{"锦江", []string{"都城"}},
+}
+
+// storeTypes are the listing type words, longest first.
+var storeTypes = []string{
+	"青年旅舍", "酒店公寓", "度假酒店", "商务酒店", "精品酒店", "主题酒店", "快捷酒店", "连锁酒店",
+	"大酒店", "度假村", "酒店", "宾馆", "旅馆", "公寓", "民宿", "客栈", "饭店",
+}
+
+// branchSuffixes end a branch name, longest first.
+var branchSuffixes = []string{"分店", "店", "站"}
+
+// regionSuffixes may follow a province or city name.
+var regionSuffixes = []string{"特别行政区", "自治区", "省", "市"}
+
+// notCityFollowers mark a city-looking prefix that is actually part of a street or landmark
+// (e.g. "中山路", "朝阳门").
+var notCityFollowers = []string{"路", "街", "大道", "大街", "公园", "门", "广场"}
+
+var (
+	versionPrefix = regexp.MustCompile(`^(\d+(\.\d+)?\p{Han}{0,2}版|\d+\.\d+\s+)`)
+	latinWord     = regexp.MustCompile(`^[A-Za-z]+$`)
+	tierSuffix    = regexp.MustCompile(`([A-Za-z][A-Za-z ]*|\d+(\.\d+)?版)$`)
+)
+
+// Listing is the structured form of a hotel listing name.
+type Listing struct {
+	Raw       string `json:"raw"`
+	Brand     string `json:"brand"`
+	SubBrand  string `json:"sub_brand,omitempty"`  // e.g. 优品, 欢朋, 国际
+	Tier      string `json:"tier,omitempty"`       // e.g. Premium, 4.0版
+	StoreType string `json:"store_type,omitempty"` // e.g. 酒店, 商务酒店
+	Province  string `json:"province,omitempty"`
+	City      string `json:"city,omitempty"`
+	District  string `json:"district,omitempty"`
+	Landmark  string `json:"landmark,omitempty"`
+	Branch    string `json:"branch,omitempty"` // full branch designation, e.g. 邢台隆尧城关店
+	Suffix    string `json:"suffix,omitempty"` // 店, 分店 or 站
+	Former    string `json:"former,omitempty"` // former name given as （原...）
+}
+
+// Parser decomposes listings using a brand list, the address gazetteer and the segmenter.
+type Parser struct {
+	seg       *segmenter.Segmenter
+	brands    map[string]bool
+	provinces map[string]bool
+	cities    map[string]bool
+	// municipalities are provinces that are also cities (北京, 香港...).
+	municipalities map[string]bool
+}
+
+// NewParser creates a parser that knows DefaultBrands plus the given brands.
+// Dictionary words tagged "brand" are recognized as well. A nil seg means a segmenter
+// over an empty dictionary.
+func NewParser(seg *segmenter.Segmenter, brands ...string) *Parser {
+	if seg == nil {
+		seg = segmenter.NewSegmenter(dictionary.NewDictionary())
+	}
+	p := &Parser{
+		seg:       seg,
+		brands:    make(map[string]bool),
+		provinces: make(map[string]bool),
+		cities:    make(map[string]bool),
+
+		municipalities: make(map[string]bool),
+	}
+	for _, prov := range address.Default().Provinces {
+		p.provinces[prov.Short()] = true
+		if prov.IsMunicipality() {
+			p.municipalities[prov.Short()] = true
+		}
+		for _, c := range prov.Children {
+			p.cities[c.Short()] = true
+		}
+	}
+	for _, b := range DefaultBrands {
+		p.AddBrand(b)
+	}
+	for _, b := range brands {
+		p.AddBrand(b)
+	}
+	if seg.Dict != nil {
+		for w, tag := range seg.Dict.Tags {
+			if tag == "brand" {
+				p.AddBrand(w)
+			}
+		}
+	}
+	return p
+}
+
+// AddBrand registers a brand. Region names (e.g. "北京市") are ignored.
+func (p *Parser) AddBrand(brand string) {
+	brand = strings.TrimSpace(brand)
+	if brand == "" {
+		return
+	}
+	if _, n := p.matchRegion(brand, p.cities); n == len(brand) {
+		return
+	}
+	if _, n := p.matchRegion(brand, p.provinces); n == len(brand) {
+		return
+	}
+	p.brands[brand] = true
+}
+
+// Parse decomposes a listing name.
+func (p *Parser) Parse(text string) Listing {
+	l := Listing{Raw: text}
+	name, branch := p.splitBranch(strings.TrimSpace(text))
+	if after, ok := strings.CutPrefix(branch, "原"); ok {
+		l.Former = after
+		branch = ""
+	}
+
+	// 1. Brand: the last known brand in the name; a brand right before it is its parent.
+	var prefix, rest string
+	matches := p.findBrands(name)
+	if len(matches) > 0 {
+		last := matches[len(matches)-1]
+		prefix, rest = name[:last.start], name[last.end:]
+		l.Brand, l.SubBrand, l.StoreType = p.splitBrand(name[last.start:last.end])
+		if len(matches) > 1 {
+			if parent := matches[len(matches)-2]; parent.end == last.start {
+				prefix = name[:parent.start]
+				l.Brand, l.SubBrand = name[parent.start:parent.end], l.Brand+l.SubBrand
+			}
+		}
+		if latinWord.MatchString(prefix) {
+			// A Latin prefix is the parent brand of a known sub-brand, e.g. ZMAX满兮.
+			l.Brand, l.SubBrand, prefix = prefix, l.Brand+l.SubBrand, ""
+		}
+	} else {
+		rest = name
+	}
+	rest = strings.Join(strings.Fields(rest), "")
+
+	// 2. Store type and tier at the end of the name. Text after an inner store type
+	// is the branch of a listing written without brackets ("希岸酒店重庆永川店").
+	if branch == "" {
+		if i, typ := indexStoreType(rest); i >= 0 && i+len(typ) < len(rest) {
+			rest, branch = rest[:i+len(typ)], rest[i+len(typ):]
+		}
+	}
+	if typ, ok := cutSuffix(rest, storeTypes); ok {
+		rest = strings.TrimSuffix(rest, typ)
+		l.StoreType = typ
+	}
+	if m := tierSuffix.FindString(rest); m != "" && (l.Brand != "" || m != rest) {
+		l.Tier = strings.TrimSpace(m)
+		rest = strings.TrimSuffix(rest, m)
+	}
+
+	// 3. Whatever is left after the brand is either a sub-brand or location text.
+	location := prefix
+	switch {
+	case rest == "":
+	case l.Brand == "" && branch != "":
+		l.Brand = rest
+	case l.Brand == "":
+		// No known brand and no separate branch: the brand is the last word before the type.
+		if _, ok := cutSuffix(rest, branchSuffixes); ok && l.StoreType == "" {
+			location += rest
+			break
+		}
+		tokens := p.words(rest)
+		if len(tokens) > 1 && utf8.RuneCountInString(tokens[len(tokens)-1]) >= 2 {
+			last := tokens[len(tokens)-1]
+			l.Brand = last
+			location += strings.TrimSuffix(rest, last)
+		} else {
+			l.Brand = rest
+		}
+	case !p.startsWithRegion(rest) && utf8.RuneCountInString(rest) <= 3:
+		l.SubBrand += rest
+	default:
+		location += rest
+	}
+	location += branch
+
+	// 4. Branch: optional version prefix, store type or branch suffix, then location parts.
+	if m := versionPrefix.FindString(location); m != "" {
+		if l.Tier == "" {
+			l.Tier = strings.TrimSpace(m)
+		}
+		location = strings.TrimPrefix(location, m)
+	}
+	if location == "" {
+		return l
+	}
+	l.Branch = location
+	if typ, ok := cutSuffix(location, storeTypes); ok {
+		location = strings.TrimSuffix(location, typ)
+		if l.StoreType == "" {
+			l.StoreType = typ
+		}
+	} else if suffix, ok := cutSuffix(location, branchSuffixes); ok {
+		l.Suffix = suffix
+		location = strings.TrimSuffix(location, suffix)
+	}
+	p.parseLocation(&l, location)
+	return l
+}
+
+// splitBranch separates the listing name from the branch given in brackets
+// ("维也纳酒店（河池凤山店）") or after a separator ("麗枫酒店·佳木斯新玛特店").
+func (p *Parser) splitBranch(text string) (string, string) {
+	if i := strings.IndexAny(text, "（("); i >= 0 {
+		name, branch := text[:i], text[i:]
+		_, size := utf8.DecodeRuneInString(branch)
+		branch = branch[size:]
+		tail := ""
+		if j := strings.IndexAny(branch, "）)"); j >= 0 {
+			_, size := utf8.DecodeRuneInString(branch[j:])
+			branch, tail = branch[:j], strings.TrimSpace(branch[j+size:])
+		}
+		// A bracketed qualifier in the middle of a name ("白玉兰（商务）酒店沧州店") is not a branch.
+		if _, ok := cutSuffix(branch, branchSuffixes); !ok && tail != "" && !strings.HasPrefix(branch, "原") {
+			return p.splitBranch(name + branch + tail)
+		}
+		return p.joinSeparated(name), strings.TrimSpace(branch)
+	}
+	runes := []rune(text)
+	for i := range runes {
+		if !isSeparator(runes, i) {
+			continue
+		}
+		name, after := string(runes[:i]), string(runes[i+1:])
+		_, isType := cutSuffix(after, storeTypes)
+		if _, isBranch := cutSuffix(after, branchSuffixes); isBranch && !isType {
+			return name, after
+		}
+	}
+	return p.joinSeparated(text), ""
+}
+
+// joinSeparated removes name separators such as the dot in "希岸·轻雅酒店".
+func (p *Parser) joinSeparated(name string) string {
+	runes := []rune(name)
+	var out []rune
+	for i, r := range runes {
+		if !isSeparator(runes, i) {
+			out = append(out, r)
+		}
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func isSeparator(runes []rune, i int) bool {
+	switch runes[i] {
+	case '·', '•', '・', '-', '—':
+		return true
+	case '.':
+		// A dot between two Han characters separates ("希岸.轻雅"); elsewhere it is a decimal point.
+		return i > 0 && i < len(runes)-1 && unicode.Is(unicode.Han, runes[i-1]) && unicode.Is(unicode.Han, runes[i+1])
+	}
+	return false
+}
+
+type brandMatch struct {
+	start, end int // byte offsets into the name
+}
+
+// findBrands returns the non-overlapping known brands of a name, scanning left to right
+// and preferring the longest brand at each position.
+func (p *Parser) findBrands(name string) []brandMatch {
+	var matches []brandMatch
+	for i := 0; i < len(name); {
+		best := 0
+		for j := i + 1; j <= len(name); j++ {
+			if j < len(name) && !utf8.RuneStart(name[j]) {
+				continue
+			}
+			w := name[i:j]
+			if !p.brands[w] {
+				continue
+			}
+			// Single-character brands (e.g. 派) only count at the start of a name.
+			if utf8.RuneCountInString(w) < 2 && i > 0 {
+				continue
+			}
+			best = j
+		}
+		if best > 0 {
+			matches = append(matches, brandMatch{i, best})
+			i = best
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(name[i:])
+		i += size
+	}
+	return matches
+}
+
+// splitBrand splits a brand written together with a known sub-brand or store type,
+// e.g. "维也纳国际" -> 维也纳 + 国际 and "维也纳酒店" -> 维也纳 + 酒店.
+func (p *Parser) splitBrand(brand string) (name, subBrand, storeType string) {
+	for _, known := range knownSubBrands {
+		rest, ok := strings.CutPrefix(brand, known.brand)
+		if !ok || rest == "" {
+			continue
+		}
+		for _, s := range known.subs {
+			if rest == s {
+				return known.brand, rest, ""
+			}
+		}
+	}
+	for _, typ := range storeTypes {
+		if parent, ok := strings.CutSuffix(brand, typ); ok && p.brands[parent] {
+			return parent, "", typ
+		}
+	}
+	return brand, "", ""
+}
+
+// parseLocation fills province, city, district and landmark from a location string such as
+// "安徽铜陵金山广场" or "济南市济阳区山东艺术设计学院".
+func (p *Parser) parseLocation(l *Listing, text string) {
+	if name, n := p.matchRegion(text, p.provinces); n > 0 {
+		l.Province = name
+		if p.municipalities[name] {
+			l.City = name
+		}
+		text = text[n:]
+	}
+	if l.City == "" {
+		if name, n := p.matchRegion(text, p.cities); n > 0 {
+			l.City = name
+			text = text[n:]
+		}
+	}
+	if text == "" {
+		return
+	}
+
+	// District: the leading word(s) of the remainder ending with 区/县/旗/市.
+	tokens := p.words(text)
+	district := ""
+	for i := 0; i < len(tokens) && i < 2; i++ {
+		district += tokens[i]
+		runes := []rune(district)
+		if len(runes) >= 2 && len(runes) <= 5 && strings.ContainsRune("区县旗市", runes[len(runes)-1]) {
+			l.District = district
+			text = strings.TrimPrefix(text, district)
+			break
+		}
+	}
+	l.Landmark = text
+}
+
+// words cuts text without the filters of the segmenter, so that the words add up to text.
+func (p *Parser) words(text string) []string {
+	var words []string
+	for _, t := range p.seg.RawTokens(text, segmenter.ModeHybrid) {
+		words = append(words, t.Text)
+	}
+	return words
+}
+
+// matchRegion matches the longest region name (with optional 省/市/... suffix) at the start of text.
+// It returns the bare name and the number of bytes consumed.
+func (p *Parser) matchRegion(text string, names map[string]bool) (string, int) {
+	best, bestLen := "", 0
+	for name := range names {
+		if !strings.HasPrefix(text, name) {
+			continue
+		}
+		n := len(name)
+		if suffix, ok := cutPrefix(text[n:], regionSuffixes); ok {
+			n += len(suffix)
+		} else if _, ok := cutPrefix(text[n:], notCityFollowers); ok {
+			continue
+		}
+		if n > bestLen || (n == bestLen && name > best) {
+			best, bestLen = name, n
+		}
+	}
+	return best, bestLen
+}
+
+func (p *Parser) startsWithRegion(text string) bool {
+	if _, n := p.matchRegion(text, p.provinces); n > 0 {
+		return true
+	}
+	_, n := p.matchRegion(text, p.cities)
+	return n > 0
+}
+
+// Brands returns the known brands, sorted.
+func (p *Parser) Brands() []string {
+	res := make([]string, 0, len(p.brands))
+	for b := range p.brands {
+		res = append(res, b)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func cutSuffix(s string, suffixes []string) (string, bool) {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return suffix, true
+		}
+	}
+	return "", false
+}
+
+func cutPrefix(s string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// indexStoreType returns the byte offset of the leftmost (longest) store type in s, or -1.
+func indexStoreType(s string) (int, string) {
+	best, bestType := -1, ""
+	for _, typ := range storeTypes {
+		i := strings.Index(s, typ)
+		if i >= 0 && (best < 0 || i < best || (i == best && len(typ) > len(bestType))) {
+			best, bestType = i, typ
+		}
+	}
+	return best, bestType
+}
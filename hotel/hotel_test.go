@@ -0,0 +1,93 @@
+package hotel
+
+import (
+	"testing"
+
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/segmenter"
+)
+
+func newTestParser() *Parser {
+	dict := dictionary.NewDictionary()
+	for _, w := range []string{"济阳区", "济阳", "山东", "艺术", "设计", "学院", "金山", "广场", "火车站", "中山路"} {
+		dict.Add(w, 10, "")
+	}
+	dict.Add("丽柏", 10, "brand")
+	dict.Total = 1000
+	return NewParser(segmenter.NewSegmenter(dict))
+}
+
+func TestParse(t *testing.T) {
+	p := newTestParser()
+	tests := []struct {
+		text string
+		want Listing
+	}{
+		{"7天优品Premium（保定中山路火车站店）", Listing{
+			Brand: "7天", SubBrand: "优品", Tier: "Premium",
+			City: "保定", Landmark: "中山路火车站", Branch: "保定中山路火车站店", Suffix: "店",
+		}},
+		{"武汉天河机场东希尔顿欢朋酒店", Listing{
+			Brand: "希尔顿", SubBrand: "欢朋", StoreType: "酒店",
+			City: "武汉", Landmark: "天河机场东", Branch: "武汉天河机场东",
+		}},
+		{"维也纳国际酒店（安徽铜陵金山广场店）", Listing{
+			Brand: "维也纳", SubBrand: "国际", StoreType: "酒店",
+			Province: "安徽", City: "铜陵", Landmark: "金山广场", Branch: "安徽铜陵金山广场店", Suffix: "店",
+		}},
+		{"IU酒店（4.0版济南市济阳区山东艺术设计学院店）", Listing{
+			Brand: "IU", Tier: "4.0版", StoreType: "酒店",
+			City: "济南", District: "济阳区", Landmark: "山东艺术设计学院", Branch: "济南市济阳区山东艺术设计学院店", Suffix: "店",
+		}},
+		{"丽柏酒店·北京西站店", Listing{
+			Brand: "丽柏", StoreType: "酒店",
+			Province: "北京", City: "北京", Landmark: "西站", Branch: "北京西站店", Suffix: "店",
+		}},
+		{"白玉兰上海闵行吴泾酒店（原锦江之星上海闵行吴泾店）", Listing{
+			Brand: "白玉兰", StoreType: "酒店",
+			Province: "上海", City: "上海", Landmark: "闵行吴泾", Branch: "上海闵行吴泾",
+			Former: "锦江之星上海闵行吴泾店",
+		}},
+	}
+	for _, tt := range tests {
+		got := p.Parse(tt.text)
+		tt.want.Raw = tt.text
+		if got != tt.want {
+			t.Errorf("Parse(%q)\n got %+v\nwant %+v", tt.text, got, tt.want)
+		}
+	}
+
+	// The filters of the segmenter do not change the parse.
+	p.seg.Filters = segmenter.FilterChain{segmenter.NewPinyinFilter(), segmenter.PunctuationFilter()}
+	for _, tt := range tests {
+		tt.want.Raw = tt.text
+		if got := p.Parse(tt.text); got != tt.want {
+			t.Errorf("Parse(%q) with filters\n got %+v\nwant %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParse_Degenerate(t *testing.T) {
+	p := newTestParser()
+	p.seg.Filters = segmenter.FilterChain{segmenter.PunctuationFilter()}
+	for _, text := range []string{"", "，，，", "！！酒店"} {
+		p.Parse(text) // must not panic
+	}
+	if got := NewParser(nil).Parse("某某小栈酒店"); got.StoreType != "酒店" {
+		t.Errorf("NewParser(nil).Parse() = %+v, want store type 酒店", got)
+	}
+}
+
+func TestAddBrand_IgnoresRegions(t *testing.T) {
+	p := newTestParser()
+	p.AddBrand("北京市")
+	p.AddBrand("亚朵")
+	for _, b := range p.Brands() {
+		if b == "北京市" {
+			t.Errorf("region name registered as brand")
+		}
+	}
+	if got := p.Parse("亚朵酒店（杭州西湖店）"); got.Brand != "亚朵" || got.City != "杭州" {
+		t.Errorf("unexpected listing %+v", got)
+	}
+}
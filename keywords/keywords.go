@@ -0,0 +1,247 @@
+// Package keywords extracts weighted keywords from text over the segmenter's words, with
+// TF-IDF or with TextRank and its key phrases.
+package keywords
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/teatak/seg/segmenter"
+	"github.com/teatak/seg/util"
+)
+
+// Keyword is an extracted word with its weight.
+type Keyword struct {
+	Word   string  `json:"word"`
+	Weight float64 `json:"weight"`
+}
+
+// IDF holds the inverse document frequency of words. Words missing from the table
+// get the median, so that an unseen word ranks like an ordinary one.
+type IDF struct {
+	Words  map[string]float64
+	Median float64
+}
+
+// NewIDF returns an empty table in which every word has weight 1 (pure term frequency).
+func NewIDF() *IDF {
+	return &IDF{Words: make(map[string]float64), Median: 1}
+}
+
+// BuildIDF computes the table from documents given as word lists:
+// idf(w) = log(N / (1 + df(w))) + 1, where df counts the documents containing w.
+func BuildIDF(docs [][]string) *IDF {
+	df := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		for _, w := range doc {
+			if !seen[w] {
+				seen[w] = true
+				df[w]++
+			}
+		}
+	}
+	idf := NewIDF()
+	n := float64(len(docs))
+	for w, c := range df {
+		idf.Words[w] = math.Log(n/float64(1+c)) + 1
+	}
+	idf.updateMedian()
+	return idf
+}
+
+// LoadIDF reads a table with one "word idf" per line, as written by Save.
+func LoadIDF(path string) (*IDF, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	idf := NewIDF()
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"word idf\"", path, lineNo)
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, lineNo, err)
+		}
+		idf.Words[fields[0]] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	idf.updateMedian()
+	return idf, nil
+}
+
+// Save writes the table sorted by descending idf.
+func (idf *IDF) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	words := make([]string, 0, len(idf.Words))
+	for w := range idf.Words {
+		words = append(words, w)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if idf.Words[words[i]] != idf.Words[words[j]] {
+			return idf.Words[words[i]] > idf.Words[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	writer := bufio.NewWriter(file)
+	for _, w := range words {
+		fmt.Fprintf(writer, "%s %.4f\n", w, idf.Words[w])
+	}
+	return writer.Flush()
+}
+
+// Get returns the idf of word, or the median when the word is unknown.
+func (idf *IDF) Get(word string) float64 {
+	if v, ok := idf.Words[word]; ok {
+		return v
+	}
+	return idf.Median
+}
+
+func (idf *IDF) updateMedian() {
+	if len(idf.Words) == 0 {
+		idf.Median = 1
+		return
+	}
+	values := make([]float64, 0, len(idf.Words))
+	for _, v := range idf.Words {
+		values = append(values, v)
+	}
+	sort.Float64s(values)
+	idf.Median = values[len(values)/2]
+}
+
+// DefaultStopWords returns the stop words embedded in the segmenter. The map is shared;
+// copy it before adding words.
+func DefaultStopWords() map[string]bool {
+	return segmenter.DefaultStopWords().Words
+}
+
+// Extractor ranks the words of a text by TF-IDF or TextRank.
+type Extractor struct {
+	Seg       *segmenter.Segmenter
+	IDF       *IDF
+	StopWords map[string]bool
+	Mode      segmenter.Mode
+	MinLen    int // shortest keyword in runes
+	Window    int // TextRank co-occurrence window in tokens
+}
+
+// NewExtractor creates an extractor with the default stop words; a nil idf means
+// pure term frequency.
+func NewExtractor(seg *segmenter.Segmenter, idf *IDF) *Extractor {
+	if idf == nil {
+		idf = NewIDF()
+	}
+	return &Extractor{Seg: seg, IDF: idf, StopWords: DefaultStopWords(), Mode: segmenter.ModeHybrid, MinLen: 2, Window: 5}
+}
+
+// LoadStopWords adds the stop words of a file with one word per line.
+func (e *Extractor) LoadStopWords(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	words := maps.Clone(e.StopWords)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if w := strings.TrimSpace(scanner.Text()); w != "" && !strings.HasPrefix(w, "#") {
+			words[w] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	e.StopWords = words
+	return nil
+}
+
+// Extract returns the topK keywords of text by descending weight (all of them when
+// topK <= 0). With allowPOS, only dictionary words tagged with one of the tags are kept,
+// e.g. Extract(text, 5, "n", "nr", "ns", "brand").
+func (e *Extractor) Extract(text string, topK int, allowPOS ...string) []Keyword {
+	tf := make(map[string]float64)
+	total := 0.0
+	for _, w := range e.Seg.Cut(text, e.Mode) {
+		if !e.candidate(w, allowPOS) {
+			continue
+		}
+		tf[w]++
+		total++
+	}
+	return rank(tf, total, topK, e.IDF.Get)
+}
+
+// candidate reports whether w may be a keyword: long enough, not a stop word, punctuation
+// or a bare number, and carrying one of allowPOS when given.
+func (e *Extractor) candidate(w string, allowPOS []string) bool {
+	if utf8.RuneCountInString(w) < e.MinLen || strings.ContainsFunc(w, unicode.IsSpace) ||
+		e.StopWords[w] || util.IsPunctuation(w) || isNumeric(w) {
+		return false
+	}
+	if len(allowPOS) == 0 {
+		return true
+	}
+	tag, _ := e.Seg.Dict.Tag(w)
+	for _, p := range allowPOS {
+		if tag == p {
+			return true
+		}
+	}
+	return false
+}
+
+// rank normalizes term frequencies, scales them by idf and keeps the topK.
+func rank(tf map[string]float64, total float64, topK int, idf func(string) float64) []Keyword {
+	result := make([]Keyword, 0, len(tf))
+	for w, c := range tf {
+		result = append(result, Keyword{Word: w, Weight: c / total * idf(w)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Weight != result[j].Weight {
+			return result[i].Weight > result[j].Weight
+		}
+		return result[i].Word < result[j].Word
+	})
+	if topK > 0 && len(result) > topK {
+		result = result[:topK]
+	}
+	return result
+}
+
+func isNumeric(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) && r != '.' && r != '%' {
+			return false
+		}
+	}
+	return true
+}
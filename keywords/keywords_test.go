@@ -0,0 +1,98 @@
+package keywords
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/segmenter"
+)
+
+func newTestExtractor(idf *IDF) *Extractor {
+	dict := dictionary.NewDictionary()
+	dict.Add("希尔顿", 100, "brand")
+	dict.Add("酒店", 500, "n")
+	dict.Add("北京", 300, "ns")
+	dict.Add("我们", 500, "r")
+	dict.Add("入住", 200, "v")
+	ex := NewExtractor(segmenter.NewSegmenter(dict), idf)
+	ex.Mode = segmenter.ModeDAG
+	return ex
+}
+
+func words(keywords []Keyword) []string {
+	var result []string
+	for _, k := range keywords {
+		result = append(result, k.Word)
+	}
+	return result
+}
+
+func TestExtract(t *testing.T) {
+	idf := BuildIDF([][]string{
+		{"希尔顿", "酒店"},
+		{"北京", "酒店"},
+		{"入住", "酒店"},
+		{"酒店"},
+	})
+	ex := newTestExtractor(idf)
+	text := "我们入住北京希尔顿酒店，希尔顿酒店2024"
+
+	// 希尔顿 occurs twice and is rarer than 酒店; 我们 is a stop word and 2024 a number.
+	got := words(ex.Extract(text, 3))
+	if want := []string{"希尔顿", "入住", "北京"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() = %v, want %v", got, want)
+	}
+
+	got = words(ex.Extract(text, 0, "brand", "ns"))
+	if want := []string{"希尔顿", "北京"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract(pos) = %v, want %v", got, want)
+	}
+}
+
+func TestIDF_SaveLoad(t *testing.T) {
+	idf := BuildIDF([][]string{{"希尔顿", "酒店"}, {"酒店"}})
+	path := filepath.Join(t.TempDir(), "idf.txt")
+	if err := idf.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadIDF(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(loaded.Get("希尔顿")-idf.Get("希尔顿")) > 1e-4 || loaded.Get("希尔顿") <= loaded.Get("酒店") {
+		t.Errorf("loaded idf = %v", loaded.Words)
+	}
+	// Unknown words get the median.
+	if loaded.Get("未知") != loaded.Median {
+		t.Errorf("Get(未知) = %v, want median %v", loaded.Get("未知"), loaded.Median)
+	}
+
+	os.WriteFile(path, []byte("希尔顿\n"), 0644)
+	if _, err := LoadIDF(path); err == nil {
+		t.Error("expected an error for a malformed line")
+	}
+}
+
+func TestTextRank(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	for _, w := range []string{"海景", "大床房", "免费", "早餐", "阳台", "步行", "海滩", "含", "双早"} {
+		dict.Add(w, 100, "n")
+	}
+	ex := NewExtractor(segmenter.NewSegmenter(dict), nil)
+	ex.Mode = segmenter.ModeDAG
+	text := "海景大床房，免费早餐，海景阳台，步行到海滩。海景大床房含双早"
+
+	got := ex.TextRank(text, 2)
+	if len(got) != 2 || got[0].Word != "海景" || got[0].Weight != 1 {
+		t.Errorf("TextRank() = %v, want 海景 first with weight 1", got)
+	}
+
+	phrases := words(ex.KeyPhrases(text, 1))
+	if want := []string{"海景大床房"}; !reflect.DeepEqual(phrases, want) {
+		t.Errorf("KeyPhrases() = %v, want %v", phrases, want)
+	}
+}
@@ -0,0 +1,136 @@
+package keywords
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	textRankDamping    = 0.85
+	textRankIterations = 30
+	textRankTolerance  = 1e-6
+)
+
+// TextRank returns the topK keywords of text ranked by TextRank over the co-occurrence graph
+// of its candidate words (all of them when topK <= 0). Two candidates are linked when they
+// occur within Window tokens of each other. No IDF table is needed, so it suits short texts
+// such as hotel or product descriptions. Weights are scaled so that the best word has 1.
+func (e *Extractor) TextRank(text string, topK int, allowPOS ...string) []Keyword {
+	words, isCandidate := e.candidates(text, allowPOS)
+	scores := e.textRankScores(words, isCandidate)
+	return rank(scores, 1, topK, func(string) float64 { return 1 })
+}
+
+// KeyPhrases returns the topK phrases made of keywords that are adjacent in the text,
+// e.g. 海景 + 大床房 -> 海景大床房. The keywords are the best third of the TextRank words
+// (at least 5), and a phrase weighs the sum of its words.
+func (e *Extractor) KeyPhrases(text string, topK int, allowPOS ...string) []Keyword {
+	words, isCandidate := e.candidates(text, allowPOS)
+	scores := e.textRankScores(words, isCandidate)
+
+	ranked := rank(scores, 1, max(5, len(scores)/3), func(string) float64 { return 1 })
+	keyword := make(map[string]float64, len(ranked))
+	for _, k := range ranked {
+		keyword[k.Word] = k.Weight
+	}
+
+	phrases := make(map[string]float64)
+	for i := 0; i < len(words); {
+		if _, ok := keyword[words[i]]; !ok {
+			i++
+			continue
+		}
+		j := i
+		weight := 0.0
+		for j < len(words) && isCandidate[j] {
+			w, ok := keyword[words[j]]
+			if !ok {
+				break
+			}
+			weight += w
+			j++
+		}
+		if j-i >= 2 {
+			phrase := strings.Join(words[i:j], "")
+			phrases[phrase] = math.Max(phrases[phrase], weight)
+		}
+		i = j
+	}
+	return rank(phrases, 1, topK, func(string) float64 { return 1 })
+}
+
+// candidates segments text and marks the tokens that may be keywords.
+func (e *Extractor) candidates(text string, allowPOS []string) ([]string, []bool) {
+	words := e.Seg.Cut(text, e.Mode)
+	isCandidate := make([]bool, len(words))
+	for i, w := range words {
+		isCandidate[i] = e.candidate(w, allowPOS)
+	}
+	return words, isCandidate
+}
+
+// textRankScores runs weighted PageRank over the co-occurrence graph of the candidates.
+func (e *Extractor) textRankScores(words []string, isCandidate []bool) map[string]float64 {
+	window := e.Window
+	if window < 2 {
+		window = 2
+	}
+	edges := make(map[string]map[string]float64)
+	link := func(a, b string) {
+		if edges[a] == nil {
+			edges[a] = make(map[string]float64)
+		}
+		edges[a][b]++
+	}
+	for i, w := range words {
+		if !isCandidate[i] {
+			continue
+		}
+		if edges[w] == nil {
+			edges[w] = make(map[string]float64)
+		}
+		for j := i + 1; j < i+window && j < len(words); j++ {
+			if isCandidate[j] && words[j] != w {
+				link(w, words[j])
+				link(words[j], w)
+			}
+		}
+	}
+	if len(edges) == 0 {
+		return nil
+	}
+
+	outWeight := make(map[string]float64, len(edges))
+	scores := make(map[string]float64, len(edges))
+	for w, neighbors := range edges {
+		for _, c := range neighbors {
+			outWeight[w] += c
+		}
+		scores[w] = 1
+	}
+	for iter := 0; iter < textRankIterations; iter++ {
+		next := make(map[string]float64, len(scores))
+		delta := 0.0
+		for w, neighbors := range edges {
+			sum := 0.0
+			for n, c := range neighbors {
+				sum += c / outWeight[n] * scores[n]
+			}
+			next[w] = 1 - textRankDamping + textRankDamping*sum
+			delta += math.Abs(next[w] - scores[w])
+		}
+		scores = next
+		if delta < textRankTolerance {
+			break
+		}
+	}
+
+	best := 0.0
+	for _, s := range scores {
+		best = math.Max(best, s)
+	}
+	for w := range scores {
+		scores[w] /= best
+	}
+	return scores
+}
@@ -0,0 +1,159 @@
+// Package ner tags named entities (person, location, organization, brand) with a BIO CRF model.
+package ner
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/teatak/seg/crf"
+)
+
+// Entity types
+const (
+	PER   = "PER"   // Person
+	LOC   = "LOC"   // Location
+	ORG   = "ORG"   // Organization
+	BRAND = "BRAND" // Brand
+)
+
+// Types lists the supported entity types.
+var Types = []string{PER, LOC, ORG, BRAND}
+
+// Labels is the BIO label set of an entity model: O followed by B-X / I-X for every type.
+var Labels = func() []string {
+	labels := []string{"O"}
+	for _, t := range Types {
+		labels = append(labels, "B-"+t, "I-"+t)
+	}
+	return labels
+}()
+
+// typeAliases maps entity type names used by common corpora (MSRA, OntoNotes, People's Daily) to ours.
+var typeAliases = map[string]string{
+	"PER": PER, "PERSON": PER, "NR": PER,
+	"LOC": LOC, "LOCATION": LOC, "GPE": LOC, "NS": LOC,
+	"ORG": ORG, "ORGANIZATION": ORG, "NT": ORG,
+	"BRAND": BRAND,
+}
+
+// posTypes maps dictionary part-of-speech tags (jieba style) to entity types.
+var posTypes = map[string]string{
+	"nr":    PER,
+	"ns":    LOC,
+	"nt":    ORG,
+	"brand": BRAND,
+}
+
+// Entity is a named entity span. Start and End are rune offsets into the text ([Start, End)).
+type Entity struct {
+	Text  string `json:"text"`
+	Type  string `json:"type"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// NewModel creates an empty entity model over Labels.
+func NewModel() *crf.Model {
+	return crf.NewModelWithLabels(Labels)
+}
+
+// TypeFromPOS returns the entity type of a dictionary part-of-speech tag (nr, ns, nt, brand), if any.
+func TypeFromPOS(tag string) (string, bool) {
+	t, ok := posTypes[tag]
+	return t, ok
+}
+
+// Extract decodes the text with an entity model and returns the entity spans.
+func Extract(m *crf.Model, text string) []Entity {
+	runes := []rune(text)
+	return Spans(m, runes, m.Decode(runes))
+}
+
+// Spans converts a BIO tag sequence into entity spans.
+func Spans(m *crf.Model, runes []rune, tags []int) []Entity {
+	var entities []Entity
+	start, typ := -1, ""
+	flush := func(end int) {
+		if start >= 0 {
+			entities = append(entities, Entity{string(runes[start:end]), typ, start, end})
+		}
+		start, typ = -1, ""
+	}
+	for i, tag := range tags {
+		label := m.TagName(tag)
+		switch {
+		case strings.HasPrefix(label, "B-"):
+			flush(i)
+			start, typ = i, label[2:]
+		case strings.HasPrefix(label, "I-") && label[2:] == typ:
+			// continue current entity
+		default:
+			flush(i)
+		}
+	}
+	flush(len(tags))
+	return entities
+}
+
+// LoadCorpus loads a character-level entity corpus in CoNLL format:
+// one "char label" pair per line and a blank line between sentences.
+// BIOES labels are folded into BIO and common type names (NR, NS, NT, GPE, PERSON...) are mapped
+// to PER/LOC/ORG; labels of unknown types are treated as O.
+func LoadCorpus(path string) ([]crf.Sentence, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	m := NewModel()
+	var data []crf.Sentence
+	var sent crf.Sentence
+	flush := func() {
+		if len(sent.Runes) > 0 {
+			data = append(data, sent)
+		}
+		sent = crf.Sentence{}
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 0 {
+			flush()
+			continue
+		}
+		runes := []rune(parts[0])
+		if len(runes) != 1 {
+			continue
+		}
+		label := "O"
+		if len(parts) >= 2 {
+			label = normalizeLabel(parts[len(parts)-1])
+		}
+		sent.Runes = append(sent.Runes, runes[0])
+		sent.Tags = append(sent.Tags, m.TagIndex(label))
+	}
+	flush()
+	return data, scanner.Err()
+}
+
+// normalizeLabel maps a BIO/BIOES label with any known type name onto Labels.
+func normalizeLabel(label string) string {
+	prefix, typ, ok := strings.Cut(strings.ToUpper(label), "-")
+	if !ok {
+		return "O"
+	}
+	t, ok := typeAliases[typ]
+	if !ok {
+		return "O"
+	}
+	switch prefix {
+	case "B", "S":
+		return "B-" + t
+	case "I", "M", "E":
+		return "I-" + t
+	}
+	return "O"
+}
@@ -0,0 +1,69 @@
+package ner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadCorpusAndExtract(t *testing.T) {
+	// Mixed BIO / BIOES labels and corpus-specific type names.
+	content := "刘 B-PER\n强 I-PER\n东 E-PER\n的 O\n京 B-NT\n东 I-NT\n物 I-NT\n流 E-NT\n\n" +
+		"马 B-PERSON\n云 I-PERSON\n在 O\n杭 B-GPE\n州 I-GPE\n\n"
+	path := filepath.Join(t.TempDir(), "ner.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sents, err := LoadCorpus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sents) != 2 {
+		t.Fatalf("len(sents) = %d, want 2", len(sents))
+	}
+
+	m := NewModel()
+	for it := 0; it < 10; it++ {
+		for _, s := range sents {
+			m.Perceptron(s, 1.0)
+		}
+	}
+
+	got := Extract(m, "刘强东的京东物流")
+	want := []Entity{
+		{"刘强东", PER, 0, 3},
+		{"京东物流", ORG, 4, 8},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() = %v, want %v", got, want)
+	}
+
+	// The same names in sentences the model was not trained on.
+	unseen := []struct {
+		text string
+		want []Entity
+	}{
+		{"马云的京东物流", []Entity{{"马云", PER, 0, 2}, {"京东物流", ORG, 3, 7}}},
+		{"刘强东在杭州", []Entity{{"刘强东", PER, 0, 3}, {"杭州", LOC, 4, 6}}},
+	}
+	for _, tt := range unseen {
+		if got := Extract(m, tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Extract(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDecode_BIOGrammar(t *testing.T) {
+	m := NewModel()
+	// Emissions prefer I-PER everywhere, which can never start an entity.
+	m.Feats["U02:A"] = map[int]float64{m.TagIndex("I-PER"): 5.0, m.TagIndex("B-PER"): 1.0}
+	m.Feats["U02:B"] = map[int]float64{m.TagIndex("I-PER"): 5.0}
+
+	got := Extract(m, "AB")
+	want := []Entity{{"AB", PER, 0, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() = %v, want %v", got, want)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/teatak/seg/address"
 	"github.com/teatak/seg/util"
 )
 
@@ -99,9 +100,9 @@ func pruneNoisyExtensions(words []Word) []Word {
 		}
 
 		// 2. Strip Tail: "希尔顿店" -> "希尔顿"
-		// Safety: Protect legitimate endings like "市", "省", "区", "店", "站"
+		// Safety: Protect legitimate endings like "市", "省", "区", "店", "站" and known place names
 		lastChar := runes[len(runes)-1]
-		if isProtectedSuffix(lastChar) {
+		if isProtectedSuffix(lastChar) || address.Default().Contains(w.Text) {
 			continue
 		}
 
@@ -124,7 +125,10 @@ func pruneNoisyExtensions(words []Word) []Word {
 }
 
 func isProtectedSuffix(r rune) bool {
-	protected := []rune("市省区县店站路里院校园")
+	if address.IsDivisionSuffix(r) {
+		return true
+	}
+	protected := []rune("店站路里院校园")
 	for _, p := range protected {
 		if r == p {
 			return true
@@ -0,0 +1,69 @@
+package optimizer
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/teatak/seg/crf"
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/keywords"
+	"github.com/teatak/seg/segmenter"
+	"github.com/teatak/seg/util"
+)
+
+// IDFFile is the IDF table used by keyword extraction.
+const IDFFile = "data/idf.txt"
+
+// ComputeIDF segments every line of the input text file as a document and writes
+// the inverse document frequency of its words to outputPath. Pass the segmenter and mode
+// of the keywords.Extractor that will use the table, so that every word it cuts (CRF words
+// included in ModeHybrid) has an entry instead of the median idf.
+func ComputeIDF(seg *segmenter.Segmenter, mode segmenter.Mode, inputPath, outputPath string) error {
+	inFile, err := os.Open(inputPath)
+	if err != nil {
+		return err
+	}
+	defer inFile.Close()
+
+	scanner := bufio.NewScanner(inFile)
+	buf := make([]byte, 1024*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	var docs [][]string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var words []string
+		for _, t := range seg.RawTokens(line, mode) {
+			if w := strings.TrimSpace(t.Text); w != "" && !util.IsPunctuation(w) {
+				words = append(words, w)
+			}
+		}
+		docs = append(docs, words)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return keywords.BuildIDF(docs).Save(outputPath)
+}
+
+// computeIDFFiles runs ComputeIDF with the dictionary and CRF model files of the pipeline in
+// the default mode of keywords.NewExtractor.
+func computeIDFFiles(inputPath, outputPath, dictPath, modelPath string) error {
+	dict := dictionary.NewDictionary()
+	if err := dict.Load(dictPath); err != nil {
+		log.Printf("Warning: ComputeIDF failed to load dictionary (using empty): %v", err)
+	}
+	seg := segmenter.NewSegmenter(dict)
+	model := crf.NewModel()
+	if err := model.Load(modelPath); err != nil {
+		log.Printf("Warning: ComputeIDF failed to load CRF model (using DAG): %v", err)
+	} else {
+		seg.CRFModel = model
+	}
+	return ComputeIDF(seg, keywords.NewExtractor(seg, nil).Mode, inputPath, outputPath)
+}
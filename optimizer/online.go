@@ -0,0 +1,55 @@
+package optimizer
+
+import (
+	"math/rand"
+
+	"github.com/teatak/seg/crf"
+)
+
+const (
+	OnlineReplaySize = 200 // corpus sentences replayed alongside each correction
+	OnlineRounds     = 5   // passes over corrections + replay sample
+	OnlineAggressive = 1.0 // PA-I step cap
+)
+
+// OnlineUpdate applies passive-aggressive updates for the corrected sentences to a copy of model.
+// Up to replaySize sentences drawn from pool with rng are replayed alongside the corrections so
+// that the update does not overwrite what the model already knows. Load the pool once (e.g. with
+// crf.LoadCorpus) and reuse it: a correction then costs no corpus parsing. A rng with a fixed
+// seed makes the update reproducible. The original model is left untouched.
+func OnlineUpdate(model *crf.Model, corrections, pool []crf.Sentence, replaySize int, rng *rand.Rand) *crf.Model {
+	replay := sampleSentences(pool, replaySize, rng)
+
+	updated := model.Clone()
+	for round := 0; round < OnlineRounds; round++ {
+		changed := false
+		for _, sent := range corrections {
+			if updated.PassiveAggressive(sent, OnlineAggressive) {
+				changed = true
+			}
+		}
+		for _, sent := range replay {
+			if updated.PassiveAggressive(sent, OnlineAggressive) {
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return updated
+}
+
+func sampleSentences(sentences []crf.Sentence, n int, rng *rand.Rand) []crf.Sentence {
+	if n <= 0 {
+		return nil
+	}
+	if n >= len(sentences) {
+		return sentences
+	}
+	sample := make([]crf.Sentence, n)
+	for i, idx := range rng.Perm(len(sentences))[:n] {
+		sample[i] = sentences[idx]
+	}
+	return sample
+}
@@ -36,7 +36,7 @@ func Run(newWordsFile string) error {
 	ensureFile(DictBase)
 
 	// 0. Load Top Brands
-	topBrands, _ := loadTopBrands(DictBase)
+	topBrands, _ := LoadTopBrands(DictBase)
 
 	// 1. Backup User Dict
 	log.Println("[1/6] Backing up user dictionary...")
@@ -113,6 +113,12 @@ func Run(newWordsFile string) error {
 		return fmt.Errorf("training failed: %w", err)
 	}
 
+	// 7.5 IDF table for keyword extraction, cut like the extractor with the new model
+	log.Println("[6.5/6] Computing IDF table for keyword extraction...")
+	if err := computeIDFFiles(TextFile, IDFFile, DictCombined, ModelFile); err != nil {
+		log.Printf("Warning: IDF computation failed: %v", err)
+	}
+
 	// Cleanup
 	os.Remove(DictCombined)
 	log.Println("=== Optimization Pipeline Completed ===")
@@ -207,7 +213,8 @@ func combineFiles(dst string, srcs ...string) error {
 	return nil
 }
 
-func loadTopBrands(path string) ([]string, error) {
+// LoadTopBrands reads the brand list of the base dictionary, one brand per line.
+func LoadTopBrands(path string) ([]string, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -4,13 +4,14 @@ import (
 	"bufio"
 	"fmt"
 	"log"
+	"math/rand"
 	"os"
 	"strings"
 
 	"github.com/teatak/seg/crf"
 	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/ner"
 	"github.com/teatak/seg/segmenter"
-	"github.com/teatak/seg/util"
 )
 
 // BatchSegment re-segments the input text file using the provided dictionary to create a training corpus.
@@ -21,6 +22,7 @@ func BatchSegment(inputPath, outputPath, dictPath string) error {
 	}
 
 	seg := segmenter.NewSegmenter(dict)
+	seg.Filters = segmenter.FilterChain{segmenter.PunctuationFilter()}
 
 	inFile, err := os.Open(inputPath)
 	if err != nil {
@@ -44,22 +46,25 @@ func BatchSegment(inputPath, outputPath, dictPath string) error {
 		if line == "" {
 			continue
 		}
-		parts := seg.Cut(line, segmenter.ModeDAG)
-		var filtered []string
-		for _, p := range parts {
-			if !util.IsPunctuation(p) {
-				filtered = append(filtered, p)
-			}
-		}
-		if len(filtered) > 0 {
-			fmt.Fprintln(writer, strings.Join(filtered, " "))
+		if words := seg.Cut(line, segmenter.ModeDAG); len(words) > 0 {
+			fmt.Fprintln(writer, strings.Join(words, " "))
 		}
 	}
 	return writer.Flush()
 }
 
+// TrainBatchPerThread is the number of sentences each thread decodes per mini-batch in parallel training.
+const TrainBatchPerThread = 16
+
 // TrainCRF trains the CRF model using the segmented corpus and optional dictionary words.
 func TrainCRF(inputPath, dictPath, outputPath string, iter int) error {
+	return TrainCRFParallel(inputPath, dictPath, outputPath, iter, 1, 0)
+}
+
+// TrainCRFParallel trains the CRF model like TrainCRF, using sharded mini-batch perceptron updates
+// when threads > 1. A non-zero seed shuffles the training set every iteration.
+// The resulting model is identical for a fixed seed and thread count.
+func TrainCRFParallel(inputPath, dictPath, outputPath string, iter, threads int, seed int64) error {
 	sentences, err := crf.LoadCorpus(inputPath)
 	if err != nil {
 		return err
@@ -74,64 +79,45 @@ func TrainCRF(inputPath, dictPath, outputPath string, iter int) error {
 	}
 
 	model := crf.NewModel()
+	trainModel(model, sentences, iter, threads, seed)
+	return model.Save(outputPath)
+}
 
-	for it := 1; it <= iter; it++ {
-		correctCnt := 0
-		totalCnt := 0
-
-		for _, sent := range sentences {
-			runes := sent.Runes
-			goldTags := sent.Tags
-
-			predTags := model.Decode(runes)
-
-			if len(runes) != len(predTags) {
-				continue
-			}
+// TrainNER trains a BIO entity model from a CoNLL-style corpus (see ner.LoadCorpus).
+func TrainNER(inputPath, outputPath string, iter, threads int, seed int64) error {
+	sentences, err := ner.LoadCorpus(inputPath)
+	if err != nil {
+		return err
+	}
+	model := ner.NewModel()
+	trainModel(model, sentences, iter, threads, seed)
+	return model.Save(outputPath)
+}
 
-			correct := true
-			for i := range goldTags {
-				if goldTags[i] != predTags[i] {
-					correct = false
-					break
-				}
-			}
+// trainModel runs perceptron training, serially or with sharded mini-batches when threads > 1.
+func trainModel(model *crf.Model, sentences []crf.Sentence, iter, threads int, seed int64) {
+	rng := rand.New(rand.NewSource(seed))
 
-			totalCnt++
-			if correct {
-				correctCnt++
-				continue
-			}
+	for it := 1; it <= iter; it++ {
+		if seed != 0 {
+			rng.Shuffle(len(sentences), func(i, j int) {
+				sentences[i], sentences[j] = sentences[j], sentences[i]
+			})
+		}
 
-			// Update Emissions
-			for i := 0; i < len(runes); i++ {
-				feats := crf.ExtractFeatures(runes, i)
-				gTag := goldTags[i]
-				pTag := predTags[i]
-
-				if gTag != pTag {
-					for _, f := range feats {
-						model.UpdateFeat(f, gTag, 1.0)
-						model.UpdateFeat(f, pTag, -1.0)
-					}
-				}
+		if threads <= 1 {
+			for _, sent := range sentences {
+				model.Perceptron(sent, 1.0)
 			}
+			continue
+		}
 
-			// Update Transitions
-			for i := 1; i < len(runes); i++ {
-				gPrev := goldTags[i-1]
-				pPrev := predTags[i-1]
-				gCurr := goldTags[i]
-				pCurr := predTags[i]
-
-				if gPrev != pPrev || gCurr != pCurr {
-					model.Trans[gPrev][gCurr] += 1.0
-					model.Trans[pPrev][pCurr] -= 1.0
-				}
-			}
+		batchSize := threads * TrainBatchPerThread
+		errors := 0
+		for lo := 0; lo < len(sentences); lo += batchSize {
+			hi := min(lo+batchSize, len(sentences))
+			errors += model.TrainBatch(sentences[lo:hi], threads, 1.0)
 		}
-		// log.Printf("Iteration %d: Accuracy %.2f%%", it, float64(correctCnt)/float64(totalCnt)*100)
+		log.Printf("Iteration %d: %d/%d sentences mispredicted", it, errors, len(sentences))
 	}
-
-	return model.Save(outputPath)
 }
@@ -0,0 +1,183 @@
+// Package pinyin converts Chinese text to Hanyu Pinyin with embedded character and phrase
+// tables. Polyphonic characters take the reading of the word they appear in (银行 yin2 hang2,
+// 行走 xing2 zou3), so text should be converted word by word after segmentation.
+package pinyin
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	//go:embed chars.txt
+	charsData string
+	//go:embed phrases.txt
+	phrasesData string
+)
+
+// Converter holds the default reading of each character and the readings of phrases.
+type Converter struct {
+	chars     map[rune]string
+	phrases   map[string][]string
+	maxPhrase int // longest phrase in runes
+}
+
+var (
+	defaultOnce sync.Once
+	defaultConv *Converter
+)
+
+// Default returns the converter built from the tables embedded in the package.
+func Default() *Converter {
+	defaultOnce.Do(func() {
+		c, err := Load(strings.NewReader(charsData), strings.NewReader(phrasesData))
+		if err != nil {
+			panic(err)
+		}
+		defaultConv = c
+	})
+	return defaultConv
+}
+
+// Convert returns the syllables of text with the default tables, see Converter.Convert.
+func Convert(text string) []string {
+	return Default().Convert(text)
+}
+
+// Load reads a character table with lines of "zhong1 中忠终..." and a phrase table with
+// lines of "银行 yin2 hang2". Tones are digits 1-5 (5 is neutral) and ü is written v.
+func Load(chars, phrases io.Reader) (*Converter, error) {
+	c := &Converter{chars: make(map[rune]string), phrases: make(map[string][]string)}
+	err := readTable(chars, func(lineNo int, fields []string) error {
+		if len(fields) != 2 {
+			return fmt.Errorf("chars line %d: expected \"拼音 汉字...\"", lineNo)
+		}
+		for _, r := range fields[1] {
+			c.chars[r] = fields[0]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if phrases == nil {
+		return c, nil
+	}
+	err = readTable(phrases, func(lineNo int, fields []string) error {
+		n := utf8.RuneCountInString(fields[0])
+		if n != len(fields)-1 {
+			return fmt.Errorf("phrases line %d: %s needs %d syllables", lineNo, fields[0], n)
+		}
+		c.phrases[fields[0]] = fields[1:]
+		if n > c.maxPhrase {
+			c.maxPhrase = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func readTable(r io.Reader, line func(lineNo int, fields []string) error) error {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if err := line(lineNo, fields); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Convert returns the syllables of text with tone numbers: 如家 -> [ru2 jia1]. Phrases are
+// matched longest first; other characters take their default reading. A run of characters
+// without a reading (letters, digits, punctuation) is kept as one lowercased element:
+// 7天 -> [7 tian1].
+func (c *Converter) Convert(text string) []string {
+	runes := []rune(text)
+	var result []string
+	for i := 0; i < len(runes); {
+		if syllables, n := c.matchPhrase(runes[i:]); n > 0 {
+			result = append(result, syllables...)
+			i += n
+			continue
+		}
+		if p, ok := c.chars[runes[i]]; ok {
+			result = append(result, p)
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && !c.HasReading(runes[j]) {
+			j++
+		}
+		if s := strings.ToLower(strings.TrimSpace(string(runes[i:j]))); s != "" {
+			result = append(result, s)
+		}
+		i = j
+	}
+	return result
+}
+
+// HasReading reports whether r is a character of the table.
+func (c *Converter) HasReading(r rune) bool {
+	_, ok := c.chars[r]
+	return ok
+}
+
+func (c *Converter) matchPhrase(runes []rune) ([]string, int) {
+	for n := min(c.maxPhrase, len(runes)); n >= 2; n-- {
+		if p, ok := c.phrases[string(runes[:n])]; ok {
+			return p, n
+		}
+	}
+	return nil, 0
+}
+
+// Plain removes the tone number of a syllable: zhong1 -> zhong.
+func Plain(syllable string) string {
+	return strings.TrimRight(syllable, "12345")
+}
+
+// Join concatenates the syllables without tones: [ru2 jia1] -> rujia.
+func Join(syllables []string) string {
+	var b strings.Builder
+	for _, s := range syllables {
+		b.WriteString(Plain(s))
+	}
+	return b.String()
+}
+
+// Initials concatenates the first letter of each syllable: [ru2 jia1] -> rj. Elements that
+// are not syllables (digits, Latin words) are kept whole: [7 tian1] -> 7t.
+func Initials(syllables []string) string {
+	var b strings.Builder
+	for _, s := range syllables {
+		r, size := utf8.DecodeRuneInString(s)
+		if size > 0 && unicode.IsLetter(r) && isSyllable(s) {
+			b.WriteRune(r)
+		} else {
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
+
+// isSyllable reports whether s looks like a toned syllable of the tables (letters then a tone).
+func isSyllable(s string) bool {
+	last := s[len(s)-1]
+	return last >= '1' && last <= '5'
+}
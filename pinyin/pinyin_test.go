@@ -0,0 +1,54 @@
+package pinyin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"如家", []string{"ru2", "jia1"}},
+		{"银行", []string{"yin2", "hang2"}},
+		{"行走", []string{"xing2", "zou3"}},
+		{"长沙", []string{"chang2", "sha1"}},
+		{"重庆", []string{"chong2", "qing4"}},
+		{"7天酒店", []string{"7", "tian1", "jiu3", "dian4"}},
+		{"Hi，北京", []string{"hi，", "bei3", "jing1"}},
+	}
+	for _, tt := range tests {
+		if got := Convert(tt.input); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Convert(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestJoinInitials(t *testing.T) {
+	syllables := Convert("如家酒店")
+	if got := Join(syllables); got != "rujiajiudian" {
+		t.Errorf("Join() = %q, want rujiajiudian", got)
+	}
+	if got := Initials(syllables); got != "rjjd" {
+		t.Errorf("Initials() = %q, want rjjd", got)
+	}
+	if got := Initials(Convert("7天")); got != "7t" {
+		t.Errorf("Initials(7天) = %q, want 7t", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	c, err := Load(strings.NewReader("hang2 行\nxing2 行\n"), strings.NewReader("银行 yin2 hang2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The later line wins the default reading.
+	if got := c.Convert("行"); !reflect.DeepEqual(got, []string{"xing2"}) {
+		t.Errorf("Convert(行) = %v", got)
+	}
+	if _, err := Load(strings.NewReader(""), strings.NewReader("银行 yin2\n")); err == nil {
+		t.Error("expected an error for a phrase with too few syllables")
+	}
+}
@@ -0,0 +1,349 @@
+// Package quantity recognizes numbers, dates, times, money, percentages, ordinals,
+// measured quantities, versions and model names in text, e.g. "一千块", "第二天",
+// "2024年5月1日", "Web3.0" and "GPT-4", so that they can be kept as single tokens.
+package quantity
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/teatak/seg/util"
+)
+
+// Match types.
+const (
+	Number   = "NUMBER"
+	Ordinal  = "ORDINAL"
+	Percent  = "PERCENT"
+	Date     = "DATE"
+	Time     = "TIME"
+	Money    = "MONEY"
+	Quantity = "QUANTITY"
+	Version  = "VERSION"
+	Model    = "MODEL"
+)
+
+// Match is a recognized span with its normalized value. Start and End are rune offsets [Start, End).
+type Match struct {
+	Text  string `json:"text"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+const (
+	arabic   = `\d+(?:,\d{3})*(?:\.\d+)?[万亿]?`
+	chinese  = `[一二两三四五六七八九十][零〇一二两三四五六七八九十百千万亿]*`
+	anyNum   = `(?:` + arabic + `|` + chinese + `)`
+	cnDigits = `[〇零一二三四五六七八九]`
+	month    = `(?:\d{1,2}|十[一二]?|[一二三四五六七八九])`
+	day      = `(?:\d{1,2}|[二三]?十[一二三四五六七八九]?|三十一|[一二三四五六七八九])`
+	hour     = `(?:\d{1,2}|二?十[一二三四]?|[零一二两三四五六七八九])`
+	minute   = `(?:\d{1,2}|[一二三四五]?十[一二三四五六七八九]?|[零一二三四五六七八九])`
+)
+
+// Measure words after 第 (第二天, 第三名) and units of measured quantities, longest first.
+var (
+	ordinalMeasures = []string{"天", "名", "次", "个", "章", "届", "页", "年", "季", "期", "回", "轮", "节", "位", "条", "层", "代", "批", "集", "部", "场", "课"}
+	units           = []string{
+		"平方米", "公里", "千米", "厘米", "毫米", "千克", "公斤", "毫升", "平米", "小时", "分钟", "个月",
+		"米", "克", "斤", "吨", "升", "秒", "天", "年", "周", "岁", "人", "个", "次", "件", "家", "间", "晚", "台", "辆", "张", "本",
+		"km", "kg", "cm", "mm", "ml", "GB", "MB", "KB", "TB",
+	}
+	currencies = map[string]string{
+		"元": "CNY", "块": "CNY", "块钱": "CNY", "人民币": "CNY", "角": "CNY", "毛": "CNY",
+		"美元": "USD", "欧元": "EUR", "英镑": "GBP", "日元": "JPY",
+		"¥": "CNY", "￥": "CNY", "$": "USD", "€": "EUR",
+	}
+)
+
+type rule struct {
+	typ       string
+	pattern   *regexp.Regexp
+	normalize func(groups []string) (string, bool)
+}
+
+// rules are tried everywhere in the text; on overlap the longest match wins, then the earlier rule.
+var rules = []rule{
+	{Date, regexp.MustCompile(`(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})`), normalizeDate},
+	{Date, regexp.MustCompile(`(\d{2,4}|` + cnDigits + `{2,4})年(` + month + `)月(?:(` + day + `)[日号])?`), normalizeDate},
+	{Date, regexp.MustCompile(`()(` + month + `)月(` + day + `)[日号]`), normalizeDate},
+	{Date, regexp.MustCompile(`(\d{4}|` + cnDigits + `{4})年()()`), normalizeDate},
+	{Time, regexp.MustCompile(`(\d{1,2}):(\d{2})(?::(\d{2}))?`), normalizeClock},
+	{Time, regexp.MustCompile(`(` + hour + `)[点时](?:(半)|整|钟|(` + minute + `)分)`), normalizeChineseTime},
+	{Money, regexp.MustCompile(`([¥￥$€])(` + arabic + `)`), normalizeMoney},
+	{Money, regexp.MustCompile(`(` + anyNum + `)(块钱|人民币|美元|欧元|英镑|日元|元|块|角|毛)`), normalizeMoney},
+	{Percent, regexp.MustCompile(`(` + arabic + `)[%％]`), normalizePercent},
+	{Percent, regexp.MustCompile(`百分之(` + anyNum + `)`), normalizePercent},
+	{Ordinal, regexp.MustCompile(`第(` + anyNum + `)(?:` + strings.Join(ordinalMeasures, "|") + `)?`), normalizeOrdinal},
+	{Quantity, regexp.MustCompile(`(` + anyNum + `)(` + strings.Join(units, "|") + `)`), normalizeQuantity},
+	{Version, regexp.MustCompile(`[A-Za-z]+\d+(?:\.\d+)+|\d+(?:\.\d+){2,}`), nil},
+	{Model, regexp.MustCompile(`[A-Za-z][A-Za-z0-9]*-\d+[A-Za-z0-9.]*`), nil},
+	{Number, regexp.MustCompile(`(` + arabic + `|` + chinese + `)`), normalizeNumber},
+}
+
+// Recognize returns the non-overlapping matches of text ordered by position.
+func Recognize(text string) []Match {
+	var candidates []Match
+	priority := make(map[Match]int)
+	for p, r := range rules {
+		for _, loc := range r.pattern.FindAllStringSubmatchIndex(text, -1) {
+			if !atBoundary(text, loc[0], loc[1]) {
+				continue
+			}
+			value := text[loc[0]:loc[1]]
+			if r.normalize != nil {
+				groups := make([]string, len(loc)/2)
+				for i := range groups {
+					if loc[2*i] >= 0 {
+						groups[i] = text[loc[2*i]:loc[2*i+1]]
+					}
+				}
+				v, ok := r.normalize(groups)
+				if !ok {
+					continue
+				}
+				value = v
+			}
+			m := Match{
+				Text:  text[loc[0]:loc[1]],
+				Type:  r.typ,
+				Value: value,
+				Start: utf8.RuneCountInString(text[:loc[0]]),
+			}
+			m.End = m.Start + utf8.RuneCountInString(m.Text)
+			if _, seen := priority[m]; !seen {
+				priority[m] = p
+				candidates = append(candidates, m)
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.End != b.End {
+			return a.End > b.End
+		}
+		return priority[a] < priority[b]
+	})
+
+	// Longest-first selection: a candidate is kept unless it overlaps a longer one.
+	return util.SelectLongest(candidates, func(m Match) (int, int) { return m.Start, m.End })
+}
+
+// atBoundary rejects matches that cut through an ASCII word, e.g. the "3.0" of "Web3.0x".
+func atBoundary(text string, start, end int) bool {
+	if start > 0 {
+		prev, _ := utf8.DecodeLastRuneInString(text[:start])
+		first, _ := utf8.DecodeRuneInString(text[start:])
+		if isASCIIAlnum(prev) && isASCIIAlnum(first) {
+			return false
+		}
+	}
+	if end < len(text) {
+		last, _ := utf8.DecodeLastRuneInString(text[:end])
+		next, _ := utf8.DecodeRuneInString(text[end:])
+		if isASCIIAlnum(last) && (isASCIIAlnum(next) || next == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCIIAlnum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func normalizeDate(g []string) (string, bool) {
+	y, m, d := g[1], g[2], g[3]
+	var parts []string
+	if y != "" {
+		n, ok := ParseNumber(y)
+		if !ok {
+			return "", false
+		}
+		parts = append(parts, fmt.Sprintf("%04d", int64(n)))
+	} else {
+		parts = append(parts, "-")
+	}
+	for _, s := range []string{m, d} {
+		if s == "" {
+			break
+		}
+		n, ok := ParseNumber(s)
+		if !ok || n < 1 || n > 31 {
+			return "", false
+		}
+		parts = append(parts, fmt.Sprintf("%02d", int64(n)))
+	}
+	if len(parts) > 1 {
+		if mo, _ := strconv.Atoi(parts[1]); mo > 12 {
+			return "", false
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+func normalizeClock(g []string) (string, bool) {
+	return formatTime(g[1], g[2], g[3])
+}
+
+func normalizeChineseTime(g []string) (string, bool) {
+	if g[2] == "半" {
+		return formatTime(g[1], "30", "")
+	}
+	return formatTime(g[1], g[3], "")
+}
+
+func formatTime(h, m, s string) (string, bool) {
+	var fields []int
+	for i, f := range []string{h, m, s} {
+		if f == "" {
+			if i < 2 {
+				fields = append(fields, 0)
+			}
+			continue
+		}
+		n, ok := ParseNumber(f)
+		if !ok || (i == 0 && n > 24) || (i > 0 && n > 59) {
+			return "", false
+		}
+		fields = append(fields, int(n))
+	}
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = fmt.Sprintf("%02d", f)
+	}
+	return strings.Join(parts, ":"), true
+}
+
+func normalizeMoney(g []string) (string, bool) {
+	amount, unit := g[1], g[2]
+	if _, ok := currencies[amount]; ok {
+		amount, unit = g[2], g[1]
+	}
+	n, ok := ParseNumber(amount)
+	if !ok {
+		return "", false
+	}
+	// "一块" alone usually means "together" (一块去), not one yuan.
+	if unit == "块" && utf8.RuneCountInString(amount) == 1 && !isASCIIAlnum([]rune(amount)[0]) {
+		return "", false
+	}
+	if unit == "角" || unit == "毛" {
+		n /= 10
+	}
+	return formatNumber(n) + " " + currencies[unit], true
+}
+
+func normalizePercent(g []string) (string, bool) {
+	n, ok := ParseNumber(g[1])
+	if !ok {
+		return "", false
+	}
+	return formatNumber(n) + "%", true
+}
+
+func normalizeOrdinal(g []string) (string, bool) {
+	n, ok := ParseNumber(g[1])
+	if !ok {
+		return "", false
+	}
+	return formatNumber(n), true
+}
+
+func normalizeQuantity(g []string) (string, bool) {
+	n, ok := ParseNumber(g[1])
+	if !ok {
+		return "", false
+	}
+	return formatNumber(n) + g[2], true
+}
+
+func normalizeNumber(g []string) (string, bool) {
+	// Bare Chinese numerals are ambiguous (十分, 一一, 五一); require a unit and two characters.
+	if r, _ := utf8.DecodeRuneInString(g[1]); !isASCIIAlnum(r) &&
+		(utf8.RuneCountInString(g[1]) < 2 || !strings.ContainsAny(g[1], "十百千万亿")) {
+		return "", false
+	}
+	n, ok := ParseNumber(g[1])
+	if !ok {
+		return "", false
+	}
+	return formatNumber(n), true
+}
+
+var cnDigitValues = map[rune]float64{
+	'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+var cnUnitValues = map[rune]float64{'十': 10, '百': 100, '千': 1000}
+
+// ParseNumber parses an Arabic ("1,024", "3.5", "1.5万") or Chinese ("一千零五", "两万", "二〇二四")
+// numeral.
+func ParseNumber(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if r, _ := utf8.DecodeRuneInString(s); r >= '0' && r <= '9' {
+		mult := 1.0
+		if rest, ok := strings.CutSuffix(s, "万"); ok {
+			s, mult = rest, 1e4
+		} else if rest, ok := strings.CutSuffix(s, "亿"); ok {
+			s, mult = rest, 1e8
+		}
+		n, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+		return n * mult, err == nil
+	}
+
+	// Digit by digit when there is no unit (二〇二四).
+	if !strings.ContainsAny(s, "十百千万亿") {
+		var n float64
+		for _, r := range s {
+			d, ok := cnDigitValues[r]
+			if !ok {
+				return 0, false
+			}
+			n = n*10 + d
+		}
+		return n, true
+	}
+
+	var total, section, num float64
+	for _, r := range s {
+		if d, ok := cnDigitValues[r]; ok {
+			num = d
+			continue
+		}
+		switch r {
+		case '十', '百', '千':
+			if num == 0 && r == '十' {
+				num = 1 // 十五 = 15
+			}
+			section += num * cnUnitValues[r]
+		case '万':
+			total += (section + num) * 1e4
+			section = 0
+		case '亿':
+			total = (total + section + num) * 1e8
+			section = 0
+		default:
+			return 0, false
+		}
+		num = 0
+	}
+	return total + section + num, true
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
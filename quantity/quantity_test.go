@@ -0,0 +1,60 @@
+package quantity
+
+import "testing"
+
+func TestRecognize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Match
+	}{
+		{"Web3.0时代GPT-4发布", []Match{
+			{Text: "Web3.0", Type: Version, Value: "Web3.0", Start: 0, End: 6},
+			{Text: "GPT-4", Type: Model, Value: "GPT-4", Start: 8, End: 13},
+		}},
+		{"第二天花了一千块", []Match{
+			{Text: "第二天", Type: Ordinal, Value: "2", Start: 0, End: 3},
+			{Text: "一千块", Type: Money, Value: "1000 CNY", Start: 5, End: 8},
+		}},
+		{"2024年5月1日 12:30:05", []Match{
+			{Text: "2024年5月1日", Type: Date, Value: "2024-05-01", Start: 0, End: 9},
+			{Text: "12:30:05", Type: Time, Value: "12:30:05", Start: 10, End: 18},
+		}},
+		{"下午3点半增长12.5%", []Match{
+			{Text: "3点半", Type: Time, Value: "03:30", Start: 2, End: 5},
+			{Text: "12.5%", Type: Percent, Value: "12.5%", Start: 7, End: 12},
+		}},
+		{"跑了3公里，人口1.5亿", []Match{
+			{Text: "3公里", Type: Quantity, Value: "3公里", Start: 2, End: 5},
+			{Text: "1.5亿", Type: Number, Value: "150000000", Start: 8, End: 12},
+		}},
+		{"二〇二四年十二月三十一日", []Match{
+			{Text: "二〇二四年十二月三十一日", Type: Date, Value: "2024-12-31", Start: 0, End: 12},
+		}},
+		// Ambiguous Chinese numerals are left alone.
+		{"十分感谢，我们一块去", nil},
+	}
+	for _, tt := range tests {
+		got := Recognize(tt.text)
+		if len(got) != len(tt.want) {
+			t.Errorf("Recognize(%q) = %+v, want %+v", tt.text, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Recognize(%q)[%d] = %+v, want %+v", tt.text, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := map[string]float64{
+		"1,024": 1024, "3.5": 3.5, "1.5万": 15000, "十五": 15, "二十": 20,
+		"一百零五": 105, "两万三千": 23000, "三亿": 3e8, "二〇二四": 2024,
+	}
+	for s, want := range tests {
+		if got, ok := ParseNumber(s); !ok || got != want {
+			t.Errorf("ParseNumber(%q) = %v, %v; want %v", s, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package resources embeds the default dictionaries and CRF model, a snapshot of data/
+// refreshed with `make resources`, so that a segmenter works without a data directory.
+// A file of the same name on disk overrides the embedded copy, layer by layer. The package
+// is optional: only binaries that import it carry the embedded files.
+package resources
+
+import (
+	"embed"
+	"io"
+	"path/filepath"
+
+	"github.com/teatak/seg/crf"
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/segmenter"
+	"github.com/teatak/seg/util"
+)
+
+//go:embed dict_core.txt dict_base.txt dict_user.txt model.crf
+var files embed.FS
+
+// Names of the embedded files, the same as in the data directory.
+const (
+	CoreFile  = "dict_core.txt"
+	BaseFile  = "dict_base.txt"
+	UserFile  = "dict_user.txt"
+	ModelFile = "model.crf"
+)
+
+// DictFiles lists the dictionary layers in load order: a word of a later layer wins.
+var DictFiles = []string{CoreFile, BaseFile, UserFile}
+
+// Open opens the embedded file name (CoreFile, ..., ModelFile).
+func Open(name string) (io.ReadCloser, error) {
+	return files.Open(name)
+}
+
+// LoadLayer loads the dictionary layer name into dict from path, or from the embedded copy
+// when path is empty or does not exist. It reports whether the embedded copy was used.
+func LoadLayer(dict *dictionary.Dictionary, name, path string) (embedded bool, err error) {
+	if path != "" && util.FileExists(path) {
+		return false, dict.Load(path)
+	}
+	f, err := Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return true, dict.Read(f, dictionary.LayerName(name))
+}
+
+// LoadModel loads the CRF model from path, or the embedded model when path is empty or
+// does not exist. It reports whether the embedded model was used.
+func LoadModel(path string) (m *crf.Model, embedded bool, err error) {
+	m = crf.NewModel()
+	if path != "" && util.FileExists(path) {
+		return m, false, m.Load(path)
+	}
+	f, err := Open(ModelFile)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	if err := m.Read(f); err != nil {
+		return nil, false, err
+	}
+	return m, true, nil
+}
+
+// Dictionary loads the core, base and user layers, each from dir when the file is there and
+// from the embedded copy otherwise. An empty dir uses only the embedded layers.
+func Dictionary(dir string) (*dictionary.Dictionary, error) {
+	dict := dictionary.NewDictionary()
+	for _, name := range DictFiles {
+		if _, err := LoadLayer(dict, name, join(dir, name)); err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
+
+// Model loads model.crf from dir, or the embedded model when it is not there.
+func Model(dir string) (*crf.Model, error) {
+	m, _, err := LoadModel(join(dir, ModelFile))
+	return m, err
+}
+
+// NewSegmenter creates a hybrid segmenter over the dictionary layers and the CRF model of
+// dir, taking the embedded copy of every missing file, then applies opts. An empty dir uses
+// only the embedded resources:
+//
+//	seg, err := resources.NewSegmenter("")     // embedded only
+//	seg, err := resources.NewSegmenter("data") // data/ overrides the embedded files
+func NewSegmenter(dir string, opts ...segmenter.Option) (*segmenter.Segmenter, error) {
+	dict, err := Dictionary(dir)
+	if err != nil {
+		return nil, err
+	}
+	model, err := Model(dir)
+	if err != nil {
+		return nil, err
+	}
+	base := []segmenter.Option{
+		segmenter.WithDictionary(dict),
+		segmenter.WithCRF(model),
+		segmenter.WithTokenizer(segmenter.HybridTokenizer{}),
+	}
+	return segmenter.New(append(base, opts...)...)
+}
+
+func join(dir, name string) string {
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, name)
+}
@@ -0,0 +1,105 @@
+package resources
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/teatak/seg/segmenter"
+)
+
+func TestDictionary_Embedded(t *testing.T) {
+	dict, err := Dictionary("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dict.Layer("全季") != "base" || dict.Layer("信息科大") != "user" {
+		t.Errorf("Layer() = %q, %q, want base, user", dict.Layer("全季"), dict.Layer("信息科大"))
+	}
+	m, err := Model("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Feats) == 0 {
+		t.Error("embedded model has no features")
+	}
+}
+
+func TestDictionary_Override(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, UserFile), []byte("测试词 100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dict, err := Dictionary(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The user layer on disk replaces the embedded one; the other layers stay embedded.
+	if dict.Layer("测试词") != "user" {
+		t.Errorf("Layer(测试词) = %q, want user", dict.Layer("测试词"))
+	}
+	if dict.Contains("信息科大") {
+		t.Error("embedded user layer should be replaced")
+	}
+	if dict.Layer("全季") != "base" {
+		t.Error("embedded base layer should be kept")
+	}
+
+	if _, embedded, err := LoadModel(filepath.Join(dir, ModelFile)); err != nil || !embedded {
+		t.Errorf("LoadModel(missing) = embedded %v, %v; want the embedded model", embedded, err)
+	}
+}
+
+func TestNewSegmenter(t *testing.T) {
+	seg, err := NewSegmenter("", segmenter.WithAlgorithm("dag"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seg.CRFModel == nil || !seg.Dict.Contains("全季") {
+		t.Fatal("NewSegmenter(\"\") should load the embedded dictionaries and model")
+	}
+	if got := seg.Cut("全季酒店"); !slices.Contains(got, "全季") {
+		t.Errorf("Cut() = %v, want 全季 as a word", got)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, UserFile), []byte("全季酒店 100000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	seg, err = NewSegmenter(dir, segmenter.WithAlgorithm("dag"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seg.Cut("全季酒店"); !reflect.DeepEqual(got, []string{"全季酒店"}) {
+		t.Errorf("Cut() with an on-disk user layer = %v, want [全季酒店]", got)
+	}
+}
+
+// The embedded files are a copy of data/; run `make resources` after training or editing
+// the dictionaries so that binaries without data/ do not ship a stale snapshot.
+func TestEmbeddedMatchesData(t *testing.T) {
+	for _, name := range append(slices.Clone(DictFiles), ModelFile) {
+		disk, err := os.ReadFile(filepath.Join("..", "data", name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		f, err := Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		embedded, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(disk, embedded) {
+			t.Errorf("resources/%s differs from data/%s, run `make resources`", name, name)
+		}
+	}
+}
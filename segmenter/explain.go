@@ -0,0 +1,175 @@
+package segmenter
+
+import (
+	"unicode/utf8"
+)
+
+// Sources of a word in an Explanation.
+const (
+	SourceDict  = "dict"  // dictionary word on the best DAG path
+	SourceChar  = "char"  // single character outside the dictionary, kept by the DAG
+	SourceCRF   = "crf"   // word cut by the CRF model
+	SourceAlnum = "alnum" // run of letters and digits
+	SourceRule  = "rule"  // pre-tokenizer pattern or recognized quantity, see Token.Type
+)
+
+// Explanation shows how a text was segmented, to debug a bad cut such as 在自 / 然: the words
+// with their source and dictionary layer, and per block of text the DAG lattice with the
+// chosen route and the CRF tag scores. Offsets refer to Norm when the text was normalized.
+// Filters are not applied.
+type Explanation struct {
+	Text   string      `json:"text"`
+	Norm   string      `json:"norm,omitempty"` // normalized text that was cut, when it differs
+	Mode   string      `json:"mode"`           // algorithm: dag, crf, hybrid, hmm or a registered one
+	Words  []WordInfo  `json:"words"`
+	Blocks []BlockInfo `json:"blocks"`
+}
+
+// WordInfo is a word of the result and where it came from.
+type WordInfo struct {
+	Token
+	Source  string  `json:"source"`
+	Layer   string  `json:"layer,omitempty"` // dictionary layer of the word (core, base, user)
+	LogProb float64 `json:"log_prob"`        // dictionary log-probability (-20 when unknown)
+}
+
+// BlockInfo explains a run of text cut by the DAG and/or the CRF model.
+type BlockInfo struct {
+	Text    string      `json:"text"`
+	Start   int         `json:"start"`
+	End     int         `json:"end"`
+	Method  string      `json:"method"`            // algorithm of the block, or alnum
+	Lattice []Candidate `json:"lattice,omitempty"` // every DAG candidate word
+	CRF     []CharScore `json:"crf,omitempty"`     // characters decoded by the CRF model
+}
+
+// Candidate is a word of the DAG lattice. Score is the log-probability of the best path
+// that starts with this word; at each position the route takes the candidate with the
+// highest score.
+type Candidate struct {
+	Word    string  `json:"word"`
+	Start   int     `json:"start"`
+	End     int     `json:"end"`
+	InDict  bool    `json:"in_dict"`
+	LogProb float64 `json:"log_prob"`
+	Score   float64 `json:"score"`
+	Chosen  bool    `json:"chosen"` // on the best route
+}
+
+// CharScore is the CRF decision for one character: the chosen tag and the emission score
+// of every tag.
+type CharScore struct {
+	Char   string             `json:"char"`
+	Pos    int                `json:"pos"`
+	Tag    string             `json:"tag"`
+	Scores map[string]float64 `json:"scores"`
+}
+
+// Explain segments text like Tokens (without Filters) and records why each word was chosen.
+func (s *Segmenter) Explain(text string, modes ...Mode) *Explanation {
+	tok := s.tokenizer(modes)
+	e := &Explanation{Text: text, Mode: tokenizerName(tok)}
+	norm := s.normalize(text).Text
+	if norm != text {
+		e.Norm = norm
+	}
+
+	runes := []rune(norm)
+	pos := 0
+	cutUntil := func(end int) {
+		s.explainText(e, runes[pos:end], pos, tok)
+		pos = end
+	}
+	for _, span := range s.atomicSpans(runes) {
+		cutUntil(span.Start)
+		e.Words = append(e.Words, WordInfo{Token: span, Source: SourceRule})
+		pos = span.End
+	}
+	cutUntil(len(runes))
+	return e
+}
+
+// explainText follows cutText over the blocks of runes, which start at offset. The lattice
+// shows the dictionary view of every block the CRF model does not cut alone, and the tag
+// scores come from the CRF decodes the tokenizer ran: a strategy decodes a run of single
+// characters or the whole block, and the tags shown are the ones its words were cut by.
+func (s *Segmenter) explainText(e *Explanation, runes []rune, offset int, tok Tokenizer) {
+	for _, block := range splitTextToBlocks(runes) {
+		start := offset
+		offset += len(block.runes)
+		b := BlockInfo{Text: string(block.runes), Start: start, End: offset, Method: e.Mode}
+		if block.isPureAlphaNum {
+			b.Method = SourceAlnum
+			e.addWord(s, b.Text, start, SourceAlnum)
+			e.Blocks = append(e.Blocks, b)
+			continue
+		}
+		if _, ok := tok.(CRFTokenizer); !ok || s.CRFModel == nil {
+			s.explainDAG(&b, block.runes, start)
+		}
+		traced := *s
+		traced.crfTrace = func(decoded []rune, tags []int) {
+			// decoded is the block or a part of it; the capacity left tells where it starts.
+			i := cap(block.runes) - cap(decoded)
+			if i < 0 || i+len(decoded) > len(block.runes) || &block.runes[i] != &decoded[0] {
+				return // a copy made by a custom strategy
+			}
+			b.CRF = append(b.CRF, s.crfScores(decoded, tags, start+i)...)
+		}
+		pos := 0
+		for _, p := range tok.Tokenize(&traced, block.runes) {
+			e.addWord(s, p.Word, start+pos, p.Source)
+			pos += utf8.RuneCountInString(p.Word)
+		}
+		e.Blocks = append(e.Blocks, b)
+	}
+}
+
+// explainDAG fills the lattice of the block.
+func (s *Segmenter) explainDAG(b *BlockInfo, runes []rune, offset int) {
+	dag := s.buildDAG(runes)
+	route := s.bestRoute(runes, dag)
+
+	chosen := make(map[[2]int]bool)
+	for i := 0; i < len(runes); i = route[i].end + 1 {
+		chosen[[2]int{i, route[i].end}] = true
+	}
+	for i, ends := range dag {
+		for _, end := range ends {
+			word := string(runes[i : end+1])
+			c := Candidate{
+				Word:    word,
+				Start:   offset + i,
+				End:     offset + end + 1,
+				InDict:  s.Dict.Contains(word),
+				LogProb: s.Dict.LogProbability(word),
+				Score:   s.Dict.LogProbability(word) + route[end+1].prob,
+				Chosen:  chosen[[2]int{i, end}],
+			}
+			b.Lattice = append(b.Lattice, c)
+		}
+	}
+}
+
+// crfScores returns the decoded tag and the emission scores of each rune.
+func (s *Segmenter) crfScores(runes []rune, tags []int, offset int) []CharScore {
+	emissions := s.CRFModel.Emissions(runes)
+	scores := make([]CharScore, len(runes))
+	for i, tag := range tags {
+		byTag := make(map[string]float64, len(emissions[i]))
+		for t, score := range emissions[i] {
+			byTag[s.CRFModel.TagName(t)] = score
+		}
+		scores[i] = CharScore{Char: string(runes[i]), Pos: offset + i, Tag: s.CRFModel.TagName(tag), Scores: byTag}
+	}
+	return scores
+}
+
+func (e *Explanation) addWord(s *Segmenter, word string, start int, source string) {
+	e.Words = append(e.Words, WordInfo{
+		Token:   Token{Text: word, Start: start, End: start + utf8.RuneCountInString(word)},
+		Source:  source,
+		Layer:   s.Dict.Layer(word),
+		LogProb: s.Dict.LogProbability(word),
+	})
+}
@@ -0,0 +1,281 @@
+package segmenter
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/pinyin"
+	"github.com/teatak/seg/util"
+)
+
+//go:embed stopwords.txt
+var stopWordsData string
+
+const (
+	// TypeSynonym marks a token added by SynonymFilter at the offsets of the word it expands.
+	TypeSynonym = "SYNONYM"
+	// TypePinyin and TypeInitials mark the full pinyin (rujia) and the initials (rj) of a word,
+	// added by PinyinFilter at its offsets.
+	TypePinyin   = "PINYIN"
+	TypeInitials = "INITIALS"
+)
+
+// Filter transforms the tokens produced by the segmenter: it may drop, rewrite or add tokens.
+type Filter interface {
+	Filter(tokens []Token) []Token
+}
+
+// FilterFunc adapts a function to the Filter interface.
+type FilterFunc func(tokens []Token) []Token
+
+// Filter calls f(tokens).
+func (f FilterFunc) Filter(tokens []Token) []Token {
+	return f(tokens)
+}
+
+// FilterChain applies its filters in order.
+type FilterChain []Filter
+
+// Filter runs the tokens through every filter of the chain.
+func (c FilterChain) Filter(tokens []Token) []Token {
+	for _, f := range c {
+		tokens = f.Filter(tokens)
+	}
+	return tokens
+}
+
+// keep returns the tokens for which pred holds, reusing the slice.
+func keep(tokens []Token, pred func(Token) bool) []Token {
+	kept := tokens[:0]
+	for _, t := range tokens {
+		if pred(t) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// PunctuationFilter drops punctuation, symbols and whitespace.
+func PunctuationFilter() Filter {
+	return FilterFunc(func(tokens []Token) []Token {
+		return keep(tokens, func(t Token) bool {
+			return strings.TrimSpace(t.Text) != "" && !util.IsPunctuation(strings.TrimSpace(t.Text))
+		})
+	})
+}
+
+// LengthFilter keeps tokens of min to max runes; max <= 0 means no upper bound.
+func LengthFilter(min, max int) Filter {
+	return FilterFunc(func(tokens []Token) []Token {
+		return keep(tokens, func(t Token) bool {
+			n := utf8.RuneCountInString(t.Text)
+			return n >= min && (max <= 0 || n <= max)
+		})
+	})
+}
+
+// LowercaseFilter lowercases the token text; offsets still refer to the original.
+func LowercaseFilter() Filter {
+	return FilterFunc(func(tokens []Token) []Token {
+		for i := range tokens {
+			tokens[i].Text = strings.ToLower(tokens[i].Text)
+		}
+		return tokens
+	})
+}
+
+// StopWordFilter drops stop words. A token also matches by its normalized form.
+type StopWordFilter struct {
+	Words map[string]bool
+}
+
+// NewStopWordFilter creates a filter for the given words.
+func NewStopWordFilter(words ...string) *StopWordFilter {
+	f := &StopWordFilter{Words: make(map[string]bool, len(words))}
+	for _, w := range words {
+		f.Words[w] = true
+	}
+	return f
+}
+
+var (
+	stopWordsOnce    sync.Once
+	defaultStopWords *StopWordFilter
+)
+
+// DefaultStopWords returns the stop word filter of the embedded list (的, 了, 我们, the, ...).
+// It is shared; use Clone before loading more words into it.
+func DefaultStopWords() *StopWordFilter {
+	stopWordsOnce.Do(func() {
+		defaultStopWords = NewStopWordFilter()
+		for _, line := range strings.Split(stopWordsData, "\n") {
+			if w := strings.TrimSpace(line); w != "" && !strings.HasPrefix(w, "#") {
+				defaultStopWords.Words[w] = true
+			}
+		}
+	})
+	return defaultStopWords
+}
+
+// Clone returns a copy of the filter that can be extended independently.
+func (f *StopWordFilter) Clone() *StopWordFilter {
+	return &StopWordFilter{Words: maps.Clone(f.Words)}
+}
+
+// Load adds the words of a file with one stop word per line; lines starting with # are comments.
+func (f *StopWordFilter) Load(path string) error {
+	return readLines(path, func(_ int, fields []string) error {
+		f.Words[fields[0]] = true
+		return nil
+	})
+}
+
+// Filter drops the stop words.
+func (f *StopWordFilter) Filter(tokens []Token) []Token {
+	return keep(tokens, func(t Token) bool {
+		return !f.Words[t.Text] && (t.Norm == "" || !f.Words[t.Norm])
+	})
+}
+
+// SynonymFilter adds the aliases of a word from the dictionary's synonym layer right after it,
+// with the same offsets and type SYNONYM, so that an index matches either spelling.
+type SynonymFilter struct {
+	Dict *dictionary.Dictionary
+}
+
+// NewSynonymFilter creates a filter over the synonyms of dict.
+func NewSynonymFilter(dict *dictionary.Dictionary) *SynonymFilter {
+	return &SynonymFilter{Dict: dict}
+}
+
+// Filter inserts the synonyms after each token that has some.
+func (f *SynonymFilter) Filter(tokens []Token) []Token {
+	var result []Token
+	for _, t := range tokens {
+		result = append(result, t)
+		if t.Type != TypeSynonym {
+			result = appendSynonyms(result, f.Dict, t)
+		}
+	}
+	return result
+}
+
+// appendSynonyms appends the aliases of t, looked up by text and then by normalized form.
+func appendSynonyms(tokens []Token, dict *dictionary.Dictionary, t Token) []Token {
+	synonyms := dict.SynonymsOf(t.Text)
+	if len(synonyms) == 0 && t.Norm != "" {
+		synonyms = dict.SynonymsOf(t.Norm)
+	}
+	for _, s := range synonyms {
+		tokens = append(tokens, Token{Text: s, Type: TypeSynonym, Start: t.Start, End: t.End})
+	}
+	return tokens
+}
+
+// PinyinFilter adds the pinyin of each Chinese word right after it, so that an index can be
+// searched by full pinyin (rujia) or initials (rj). Polyphones take the reading of the segmented
+// word: 银行 gives yinhang, 行走 gives xingzou.
+type PinyinFilter struct {
+	Converter *pinyin.Converter // nil means the embedded tables
+	Initials  bool              // also add the initials token
+}
+
+// NewPinyinFilter creates a filter over the embedded tables that adds both tokens.
+func NewPinyinFilter() *PinyinFilter {
+	return &PinyinFilter{Initials: true}
+}
+
+// Filter inserts the pinyin tokens after each token that contains Chinese characters.
+func (f *PinyinFilter) Filter(tokens []Token) []Token {
+	conv := f.Converter
+	if conv == nil {
+		conv = pinyin.Default()
+	}
+	var result []Token
+	for _, t := range tokens {
+		result = append(result, t)
+		if t.Type == TypePinyin || t.Type == TypeInitials || !strings.ContainsFunc(t.Text, isCJK) {
+			continue
+		}
+		word := t.Text
+		if t.Norm != "" {
+			word = t.Norm
+		}
+		syllables := conv.Convert(word)
+		full := pinyin.Join(syllables)
+		result = append(result, Token{Text: full, Type: TypePinyin, Start: t.Start, End: t.End})
+		if initials := pinyin.Initials(syllables); f.Initials && initials != full {
+			result = append(result, Token{Text: initials, Type: TypeInitials, Start: t.Start, End: t.End})
+		}
+	}
+	return result
+}
+
+// FilterRegistry names the filters that requests may pick, e.g. "punct" or "stopwords".
+type FilterRegistry map[string]Filter
+
+// NewFilterRegistry returns "punct", "lowercase", "stopwords" with the embedded list,
+// "synonyms" over the synonym layer of dict and "pinyin". Callers may replace "stopwords"
+// after loading more words.
+func NewFilterRegistry(dict *dictionary.Dictionary) FilterRegistry {
+	return FilterRegistry{
+		"punct":     PunctuationFilter(),
+		"lowercase": LowercaseFilter(),
+		"stopwords": DefaultStopWords(),
+		"synonyms":  NewSynonymFilter(dict),
+		"pinyin":    NewPinyinFilter(),
+	}
+}
+
+// Chain builds a chain of the named filters in the given order.
+func (r FilterRegistry) Chain(names ...string) (FilterChain, error) {
+	var chain FilterChain
+	for _, name := range names {
+		f, ok := r[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q (available: %s)", name, strings.Join(r.Names(), ", "))
+		}
+		chain = append(chain, f)
+	}
+	return chain, nil
+}
+
+// Names returns the registered filter names in sorted order.
+func (r FilterRegistry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readLines(path string, line func(lineNo int, fields []string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if err := line(lineNo, fields); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,107 @@
+package segmenter
+
+import (
+	"github.com/teatak/seg/crf"
+	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/hmm"
+	"github.com/teatak/seg/util"
+	"github.com/teatak/seg/zhconv"
+)
+
+// Option configures a Segmenter built by New.
+type Option func(*Segmenter) error
+
+// New creates a segmenter from options. Without options it is NewSegmenter over an empty
+// dictionary: the default normalizer and pre-tokenizer, and no Tokenizer, so calls without a
+// mode use the DAG. WithAlgorithm picks another one:
+//
+//	seg, err := segmenter.New(
+//		segmenter.WithDictionary(dict),
+//		segmenter.WithCRF(model),
+//		segmenter.WithAlgorithm("hybrid"),
+//	)
+func New(opts ...Option) (*Segmenter, error) {
+	s := NewSegmenter(dictionary.NewDictionary())
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// WithDictionary sets the dictionary.
+func WithDictionary(dict *dictionary.Dictionary) Option {
+	return func(s *Segmenter) error {
+		s.Dict = dict
+		return nil
+	}
+}
+
+// WithCRF sets the CRF model used by the crf and hybrid tokenizers.
+func WithCRF(model *crf.Model) Option {
+	return func(s *Segmenter) error {
+		s.CRFModel = model
+		return nil
+	}
+}
+
+// WithHMM sets the HMM used by the hmm tokenizer, and by hybrid without a CRF model.
+func WithHMM(model *hmm.Model) Option {
+	return func(s *Segmenter) error {
+		s.HMMModel = model
+		return nil
+	}
+}
+
+// WithFilters sets the filter chain applied to the tokens.
+func WithFilters(filters ...Filter) Option {
+	return func(s *Segmenter) error {
+		s.Filters = filters
+		return nil
+	}
+}
+
+// WithNormalizer sets the normalizer; nil disables normalization.
+func WithNormalizer(n *util.Normalizer) Option {
+	return func(s *Segmenter) error {
+		s.Normalizer = n
+		return nil
+	}
+}
+
+// WithConverter sets the traditional to simplified converter; nil disables conversion.
+func WithConverter(c *zhconv.Converter) Option {
+	return func(s *Segmenter) error {
+		s.Converter = c
+		return nil
+	}
+}
+
+// WithTokenizer sets the algorithm used when no mode is given.
+func WithTokenizer(t Tokenizer) Option {
+	return func(s *Segmenter) error {
+		s.Tokenizer = t
+		return nil
+	}
+}
+
+// WithAlgorithm selects a registered tokenizer by name, see TokenizerByName.
+func WithAlgorithm(name string) Option {
+	return func(s *Segmenter) error {
+		t, err := TokenizerByName(name)
+		if err != nil {
+			return err
+		}
+		s.Tokenizer = t
+		return nil
+	}
+}
+
+// WithStrategy sets the hybrid strategy.
+func WithStrategy(st Strategy) Option {
+	return func(s *Segmenter) error {
+		s.Strategy = st
+		return nil
+	}
+}
@@ -0,0 +1,122 @@
+package segmenter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/teatak/seg/util"
+)
+
+// Token types of the built-in pre-tokenizer patterns.
+const (
+	TypeURL     = "URL"
+	TypeEmail   = "EMAIL"
+	TypeHashtag = "HASHTAG"
+	TypeMention = "MENTION"
+	TypeEmoji   = "EMOJI"
+)
+
+const (
+	// urlStop ends a URL: whitespace, quotes, brackets and CJK punctuation.
+	urlStop = `\s<>"'，。！？；：、（）()【】「」《》`
+	// emojiBase is a pictograph, or a symbol of the technical, misc symbols, dingbats and arrows
+	// blocks that shows as emoji by default (⌚ ☕ ⚡ ✅ ⭐); the other symbols of those blocks
+	// (☆ ★ ♥ ✓) are emoji only when followed by U+FE0F. Flags are pairs of regional indicators.
+	emojiBase = `(?:[\x{1F000}-\x{1FAFF}` +
+		`\x{231A}\x{231B}\x{23E9}-\x{23EC}\x{23F0}\x{23F3}\x{25FD}\x{25FE}\x{2614}\x{2615}` +
+		`\x{2648}-\x{2653}\x{267F}\x{2693}\x{26A1}\x{26AA}\x{26AB}\x{26BD}\x{26BE}\x{26C4}` +
+		`\x{26C5}\x{26CE}\x{26D4}\x{26EA}\x{26F2}\x{26F3}\x{26F5}\x{26FA}\x{26FD}\x{2705}` +
+		`\x{270A}\x{270B}\x{2728}\x{274C}\x{274E}\x{2753}-\x{2755}\x{2757}\x{2795}-\x{2797}` +
+		`\x{27B0}\x{27BF}\x{2B1B}\x{2B1C}\x{2B50}\x{2B55}]` +
+		`|[\x{2300}-\x{23FF}\x{2600}-\x{27BF}\x{2B00}-\x{2BFF}]\x{FE0F})`
+	emojiMod = `[\x{1F3FB}-\x{1F3FF}\x{FE0F}\x{20E3}\x{E0020}-\x{E007F}]`
+)
+
+// Pattern is a regular expression whose matches are kept as single tokens of the given type.
+type Pattern struct {
+	Type   string
+	Regexp *regexp.Regexp
+}
+
+// DefaultPatterns returns the built-in patterns: URLs, emails, #话题#, @用户 and emoji sequences.
+func DefaultPatterns() []Pattern {
+	return []Pattern{
+		{TypeURL, regexp.MustCompile(`(?i)(?:https?|ftp)://[^` + urlStop + `]*[^` + urlStop + `.,;:!?]|www\.[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+(?:/[^` + urlStop + `]*[^` + urlStop + `.,;:!?])?`)},
+		{TypeEmail, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)},
+		{TypeHashtag, regexp.MustCompile(`#[^#\s]{1,50}#|#[\p{Han}A-Za-z0-9_]+`)},
+		{TypeMention, regexp.MustCompile(`@[\p{Han}A-Za-z0-9_-]{1,30}`)},
+		{TypeEmoji, regexp.MustCompile(`[\x{1F1E6}-\x{1F1FF}]{2}|` + emojiBase + emojiMod + `*(?:\x{200D}` + emojiBase + emojiMod + `*)*`)},
+	}
+}
+
+// PreTokenizer finds spans of the text that must not be segmented, such as URLs and emails.
+// Where matches overlap, the longest wins, then the pattern registered first.
+type PreTokenizer struct {
+	Patterns []Pattern
+}
+
+// NewPreTokenizer creates a pre-tokenizer with the built-in patterns.
+func NewPreTokenizer() *PreTokenizer {
+	return &PreTokenizer{Patterns: DefaultPatterns()}
+}
+
+// Register adds a user pattern, e.g. Register("ORDER", `SO\d{8}`).
+func (p *PreTokenizer) Register(typ, expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	p.Patterns = append(p.Patterns, Pattern{Type: typ, Regexp: re})
+	return nil
+}
+
+// LoadPatterns registers the patterns of a file with one "TYPE regexp" per line.
+func (p *PreTokenizer) LoadPatterns(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		typ, expr, ok := strings.Cut(line, " ")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected \"TYPE regexp\"", path, lineNo)
+		}
+		if err := p.Register(typ, strings.TrimSpace(expr)); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Split returns the non-overlapping pattern matches of text ordered by position.
+func (p *PreTokenizer) Split(text string) []Token {
+	var candidates []Token
+	for _, pat := range p.Patterns {
+		for _, loc := range pat.Regexp.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			start := utf8.RuneCountInString(text[:loc[0]])
+			candidates = append(candidates, Token{
+				Text:  text[loc[0]:loc[1]],
+				Type:  pat.Type,
+				Start: start,
+				End:   start + utf8.RuneCountInString(text[loc[0]:loc[1]]),
+			})
+		}
+	}
+	return util.SelectLongest(candidates, func(t Token) (int, int) { return t.Start, t.End })
+}
@@ -2,9 +2,17 @@ package segmenter
 
 import (
 	"math"
+	"slices"
+	"sort"
+	"unicode/utf8"
 
 	"github.com/teatak/seg/crf"
 	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/hmm"
+	"github.com/teatak/seg/ner"
+	"github.com/teatak/seg/quantity"
+	"github.com/teatak/seg/util"
+	"github.com/teatak/seg/zhconv"
 )
 
 // Mode defines the segmentation mode.
@@ -16,63 +24,219 @@ const (
 	ModeHybrid             // ModeHybrid uses a hybrid approach: Dictionary-first, then CRF for OOV.
 )
 
+// String returns the name of the mode as used by the CLI and the API: dag, crf or hybrid.
+func (m Mode) String() string {
+	switch m {
+	case ModeCRF:
+		return "crf"
+	case ModeHybrid:
+		return "hybrid"
+	default:
+		return "dag"
+	}
+}
+
 // Segmenter handles the text segmentation.
 type Segmenter struct {
 	Dict     *dictionary.Dictionary
 	CRFModel *crf.Model
+	NERModel *crf.Model // optional BIO entity model, see Entities
+	// Normalizer folds full-width and compatibility characters before segmentation (nil disables it).
+	// Tokens keep the original text and offsets.
+	Normalizer *util.Normalizer
+	// PreTokenizer keeps URLs, emails, hashtags, mentions, emoji and user patterns whole (nil disables it).
+	PreTokenizer *PreTokenizer
+	// Converter turns traditional input into simplified before segmentation so that it is cut
+	// with the simplified dictionary and model (nil disables it). Tokens keep the original
+	// script; Norm holds the simplified form.
+	Converter *zhconv.Converter
+	// Filters post-process the tokens of Tokens, Cut and CutSearch: stop words, punctuation,
+	// length, case, synonyms. An empty chain keeps every token.
+	Filters  FilterChain
+	HMMModel *hmm.Model // optional HMM for the "hmm" tokenizer, and for hybrid without a CRF model
+	// Tokenizer is the algorithm used when no mode is given (nil means DAG), see TokenizerByName.
+	Tokenizer Tokenizer
+	// Strategy merges the dictionary and the CRF model in ModeHybrid (nil means TrustLength).
+	Strategy Strategy
+	// DisableRules turns off the rule-based recognition of numbers, dates, money,
+	// versions and model names that otherwise runs ahead of the dictionary. Rule matches that
+	// would cut a longer dictionary word (一家人, 一次性) are left to the dictionary.
+	DisableRules bool
+
+	// crfTrace, when set, sees every CRF decode of the tokenizers, see Explain.
+	crfTrace func(runes []rune, tags []int)
 }
 
-// NewSegmenter creates a new segmenter with the given dictionary.
+// Token is a segment of the text with rune offsets [Start, End). Type names the pattern or rule
+// that produced an atomic token (URL, EMOJI, DATE, MONEY, ...) and is empty for ordinary words.
+type Token struct {
+	Text  string `json:"text"`
+	Norm  string `json:"norm,omitempty"` // normalized text, when it differs from Text
+	Type  string `json:"type,omitempty"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// NewSegmenter creates a new segmenter with the given dictionary, the default normalizer and pre-tokenizer.
 func NewSegmenter(dict *dictionary.Dictionary) *Segmenter {
-	return &Segmenter{Dict: dict}
+	return &Segmenter{Dict: dict, Normalizer: util.NewNormalizer(), PreTokenizer: NewPreTokenizer()}
 }
 
-// Cut segments the text into a slice of strings using the specified mode (defaults to ModeDAG).
+// Cut segments the text into a slice of strings using the specified mode, or the Tokenizer
+// of the segmenter when no mode is given (ModeDAG when it has none).
 func (s *Segmenter) Cut(text string, modes ...Mode) []string {
-	mode := ModeDAG
-	if len(modes) > 0 {
-		mode = modes[0]
+	return texts(s.Tokens(text, modes...))
+}
+
+// Tokens segments the text like Cut and returns the tokens with their offsets and types.
+func (s *Segmenter) Tokens(text string, modes ...Mode) []Token {
+	tokens := s.RawTokens(text, modes...)
+	if len(s.Filters) > 0 {
+		tokens = s.Filters.Filter(tokens)
+	}
+	return tokens
+}
+
+// RawTokens segments the text like Tokens without applying Filters, so that the tokens cover
+// the text in order. Parsers that rely on the offsets or on every word use it.
+func (s *Segmenter) RawTokens(text string, modes ...Mode) []Token {
+	return s.normalizedTokens(text, s.tokenizer(modes))
+}
+
+// normalize applies the Normalizer and the Converter to text.
+func (s *Segmenter) normalize(text string) util.Normalized {
+	norm := util.Normalized{Text: text}
+	if s.Normalizer != nil {
+		norm = s.Normalizer.Normalize(text)
+	}
+	if s.Converter != nil {
+		// Conversion is character for character, so the offsets still hold.
+		norm.Text = s.Converter.ToSimplified(norm.Text)
+	}
+	return norm
+}
+
+// normalizedTokens segments the normalized and converted text and maps the tokens back.
+func (s *Segmenter) normalizedTokens(text string, tok Tokenizer) []Token {
+	norm := s.normalize(text)
+	if norm.Text == text {
+		return s.tokens(text, tok)
+	}
+
+	// Segment the normalized text and map the tokens back to the original.
+	runes := []rune(text)
+	var tokens []Token
+	for _, t := range s.tokens(norm.Text, tok) {
+		start, end := t.Start, t.End
+		if norm.Offsets != nil {
+			start, end = norm.Original(t.Start, t.End)
+		}
+		if end <= start {
+			continue // part of an expanded character (ﬁ -> f i); the next token covers it
+		}
+		t.Norm, t.Text = t.Text, string(runes[start:end])
+		if t.Norm == t.Text {
+			t.Norm = ""
+		}
+		t.Start, t.End = start, end
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+func (s *Segmenter) tokens(text string, tok Tokenizer) []Token {
+
+	// Pattern matches and recognized numbers, dates, money etc. are kept whole;
+	// the text around them is cut as usual.
+	var tokens []Token
+	runes := []rune(text)
+	pos := 0
+	cutUntil := func(end int) {
+		for _, w := range s.cutText(string(runes[pos:end]), tok) {
+			n := utf8.RuneCountInString(w)
+			tokens = append(tokens, Token{Text: w, Start: pos, End: pos + n})
+			pos += n
+		}
 	}
+	for _, span := range s.atomicSpans(runes) {
+		cutUntil(span.Start)
+		tokens = append(tokens, span)
+		pos = span.End
+	}
+	cutUntil(len(runes))
+	return tokens
+}
+
+// atomicSpans returns the pre-tokenizer matches, then the rule matches in the text between them.
+func (s *Segmenter) atomicSpans(runes []rune) []Token {
+	var patterns []Token
+	if s.PreTokenizer != nil {
+		patterns = s.PreTokenizer.Split(string(runes))
+	}
+	if s.DisableRules {
+		return patterns
+	}
+	var spans []Token
+	pos := 0
+	addRules := func(end int) {
+		for _, m := range quantity.Recognize(string(runes[pos:end])) {
+			if s.crossesLongerWord(runes, pos+m.Start, pos+m.End) {
+				continue // 一家人, 一次性, 三人行: the dictionary word wins
+			}
+			spans = append(spans, Token{Text: m.Text, Type: m.Type, Start: pos + m.Start, End: pos + m.End})
+		}
+	}
+	for _, p := range patterns {
+		addRules(p.Start)
+		spans = append(spans, p)
+		pos = p.End
+	}
+	addRules(len(runes))
+	return spans
+}
+
+// crossesLongerWord tells whether a dictionary word longer than runes [start, end) overlaps it.
+func (s *Segmenter) crossesLongerWord(runes []rune, start, end int) bool {
+	if s.Dict == nil {
+		return false
+	}
+	for i := max(0, start-s.Dict.MaxLen+1); i < end; i++ {
+		for j := max(i+end-start+1, start+1); j <= len(runes) && j-i <= s.Dict.MaxLen; j++ {
+			if s.Dict.Contains(string(runes[i:j])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Quantities returns the numbers, dates, times, money amounts, versions and model names of the text.
+func (s *Segmenter) Quantities(text string) []quantity.Match {
+	return quantity.Recognize(text)
+}
 
+// cutText cuts the runs of word characters and of other characters with tok; runs of
+// letters and digits are kept whole.
+func (s *Segmenter) cutText(text string, tok Tokenizer) []string {
 	runes := []rune(text)
 	blocks := splitTextToBlocks(runes)
 	var result []string
 	for _, block := range blocks {
 		if block.isPureAlphaNum {
 			result = append(result, string(block.runes))
-		} else {
-			switch mode {
-			case ModeCRF:
-				if s.CRFModel != nil {
-					result = append(result, s.cutCRF(string(block.runes))...)
-				} else {
-					// Fallback if no model
-					result = append(result, s.cutDAG(string(block.runes))...)
-				}
-			case ModeHybrid:
-				if s.CRFModel != nil {
-					result = append(result, s.cutHybrid(string(block.runes))...)
-				} else {
-					result = append(result, s.cutDAG(string(block.runes))...)
-				}
-			default:
-				result = append(result, s.cutDAG(string(block.runes))...)
-			}
+			continue
+		}
+		for _, p := range tok.Tokenize(s, block.runes) {
+			result = append(result, p.Word)
 		}
 	}
 	return result
 }
 
-// cutDAG implements the original DAG based segmentation
-func (s *Segmenter) cutDAG(text string) []string {
-	runes := []rune(text)
+// buildDAG returns, for each position, the end indices (inclusive) of the candidate words
+// starting there.
+func (s *Segmenter) buildDAG(runes []rune) [][]int {
 	n := len(runes)
-	if n == 0 {
-		return []string{}
-	}
-
-	// 1. Build DAG
-	// dag[i] contains a list of end indices (inclusive) for words starting at i
 	dag := make([][]int, n)
 	for i := 0; i < n; i++ {
 		dag[i] = []int{}
@@ -112,12 +276,20 @@ func (s *Segmenter) cutDAG(text string) []string {
 			dag[i] = append(dag[i], i)
 		}
 	}
+	return dag
+}
 
-	// 2. Dynamic Programming for Max Probability Path
-	type routeNode struct {
-		prob float64
-		end  int
-	}
+// routeNode is the best path from a position to the end of the text: its log-probability
+// and the end index (inclusive) of its first word.
+type routeNode struct {
+	prob float64
+	end  int
+}
+
+// bestRoute finds the maximum probability path through the DAG by dynamic programming
+// from the end of the text.
+func (s *Segmenter) bestRoute(runes []rune, dag [][]int) []routeNode {
+	n := len(runes)
 	route := make([]routeNode, n+1)
 	route[n] = routeNode{prob: 0, end: 0}
 
@@ -143,37 +315,69 @@ func (s *Segmenter) cutDAG(text string) []string {
 
 		route[i] = routeNode{prob: bestProb, end: bestEnd}
 	}
+	return route
+}
 
-	// 3. Backtrack to generating result
-	var result []string
-	idx := 0
-	for idx < n {
-		end := route[idx].end
-		word := string(runes[idx : end+1])
-		result = append(result, word)
-		idx = end + 1
-	}
+// CutSearch segments the text into a slice of strings, including fine-grained sub-words, using the specified mode (see Cut).
+// Typical usage: for search engine indexing.
+func (s *Segmenter) CutSearch(text string, modes ...Mode) []string {
+	return texts(s.SearchTokens(text, modes...))
+}
 
+// SearchTokens is CutSearch with offsets: each token is preceded by its dictionary sub-words.
+func (s *Segmenter) SearchTokens(text string, modes ...Mode) []Token {
+	result := []Token{}
+	for _, t := range s.Tokens(text, modes...) {
+		result = s.appendSubWords(result, t)
+		result = append(result, t)
+	}
 	return result
 }
 
-// CutSearch segments the text into a slice of strings, including fine-grained sub-words, using the specified mode (defaults to ModeDAG).
-// Typical usage: for search engine indexing.
-func (s *Segmenter) CutSearch(text string, modes ...Mode) []string {
-	result := []string{}
-	defaultSegs := s.Cut(text, modes...)
+// CutSearchExpand is CutSearch for query expansion: every word is followed by its aliases
+// from the dictionary's synonym layer (7天 -> 七天, 欢朋 -> 希尔顿欢朋), so that an index built
+// with it matches every spelling. Sub-words are not expanded, and an alias that the word or
+// its sub-words already cover is left out, so each spelling appears once per word.
+func (s *Segmenter) CutSearchExpand(text string, modes ...Mode) []string {
+	return texts(s.ExpandTokens(text, modes...))
+}
+
+// ExpandTokens is CutSearchExpand with offsets; a synonym has type SYNONYM and the offsets of
+// the word it stands for.
+func (s *Segmenter) ExpandTokens(text string, modes ...Mode) []Token {
+	var result []Token
+	for _, t := range s.Tokens(text, modes...) {
+		group := append(s.appendSubWords(nil, t), t)
+		result = append(result, group...)
+		if t.Type == TypeSynonym {
+			continue
+		}
+		seen := make(map[string]bool, len(group))
+		for _, g := range group {
+			seen[g.Text] = true
+		}
+		for _, alias := range appendSynonyms(nil, s.Dict, t) {
+			if !seen[alias.Text] {
+				seen[alias.Text] = true
+				result = append(result, alias)
+			}
+		}
+	}
+	return result
+}
 
-	for _, word := range defaultSegs {
-		s.addSubWords(word, &result)
-		result = append(result, word)
+func texts(tokens []Token) []string {
+	result := make([]string, len(tokens))
+	for i, t := range tokens {
+		result[i] = t.Text
 	}
 	return result
 }
 
-func (s *Segmenter) addSubWords(word string, result *[]string) {
-	runes := []rune(word)
-	if len(runes) <= 2 {
-		return
+func (s *Segmenter) appendSubWords(result []Token, t Token) []Token {
+	runes := []rune(t.Text)
+	if len(runes) <= 2 || t.Type == TypeSynonym {
+		return result
 	}
 
 	// 英文或数字单词不进行子词切分 (如 PKU 不要切出 P/K/U)
@@ -185,71 +389,45 @@ func (s *Segmenter) addSubWords(word string, result *[]string) {
 		}
 	}
 	if isPure {
-		return
+		return result
 	}
 
+	// Traditional words are looked up in simplified form; sub-words keep the original script.
+	lookup := runes
+	if s.Converter != nil {
+		lookup = []rune(s.Converter.ToSimplified(t.Text))
+	}
+	// Sub-word offsets are exact when the token text spans its offsets rune for rune.
+	exact := len(runes) == t.End-t.Start
 	for i := 0; i < len(runes); i++ {
 		for j := i + 1; j <= len(runes); j++ {
-			subWord := string(runes[i:j])
-			if subWord != word && s.Dict.Contains(subWord) {
-				*result = append(*result, subWord)
+			if j-i == len(runes) || !s.Dict.Contains(string(lookup[i:j])) {
+				continue
 			}
+			sub := Token{Text: string(runes[i:j]), Start: t.Start, End: t.End}
+			if exact {
+				sub.Start, sub.End = t.Start+i, t.Start+j
+			}
+			result = append(result, sub)
 		}
 	}
-}
-
-// cutHybrid segments the text using a hybrid approach: Dictionary-first, then CRF for OOV.
-func (s *Segmenter) cutHybrid(text string) []string {
-	if s.CRFModel == nil {
-		return s.cutDAG(text) // Fallback to DAG if no model
-	}
-
-	// Hybrid Strategy:
-	// 1. Identify words that are definitely in the dictionary (Trust high freq words).
-	// 2. Use CRF only for the gaps (Unknown segments).
-
-	dagTokens := s.cutDAG(text)
-
-	var result []string
-	var buf []rune
-
-	flushBuf := func() {
-		if len(buf) == 0 {
-			return
-		}
-		predictions := s.decodeCRFBlock(buf)
-		result = append(result, predictions...)
-		buf = []rune{}
-	}
-
-	for _, token := range dagTokens {
-		r := []rune(token)
-		if len(r) > 1 {
-			// Trusted long word
-			flushBuf()
-			result = append(result, token)
-		} else {
-			// Single char - potential OOV part
-			buf = append(buf, r...)
-		}
-	}
-	flushBuf()
-
 	return result
 }
 
-// cutCRF segments the text using pure CRF model-based segmentation.
-func (s *Segmenter) cutCRF(text string) []string {
-	runes := []rune(text)
-	return s.decodeCRFBlock(runes)
-}
-
 // Low-level CRF decoder for a run of text
 func (s *Segmenter) decodeCRFBlock(runes []rune) []string {
 	if len(runes) == 0 {
 		return nil
 	}
 	tags := s.CRFModel.Decode(runes)
+	if s.crfTrace != nil {
+		s.crfTrace(runes, tags)
+	}
+	return crfWords(runes, tags)
+}
+
+// crfWords groups the runes into words by their BMES tags.
+func crfWords(runes []rune, tags []int) []string {
 	var res []string
 	var buf []rune
 	for i, tag := range tags {
@@ -269,7 +447,6 @@ func (s *Segmenter) decodeCRFBlock(runes []rune) []string {
 			buf = []rune{}
 		case crf.TagS:
 			if len(buf) > 0 {
-				res = append(res, string(buf))
 				res = append(res, string(buf))
 				buf = []rune{}
 			}
@@ -281,3 +458,41 @@ func (s *Segmenter) decodeCRFBlock(runes []rune) []string {
 	}
 	return res
 }
+
+// Entities returns the named entities (PER/LOC/ORG/BRAND) of the text with rune offsets.
+// Spans come from NERModel when it is loaded; dictionary words whose tag names an entity
+// type (nr, ns, nt, brand) are added where they do not overlap a model span.
+func (s *Segmenter) Entities(text string) []ner.Entity {
+	var entities []ner.Entity
+	runes := []rune(text)
+	covered := make([]bool, len(runes))
+	if s.NERModel != nil {
+		entities = ner.Extract(s.NERModel, text)
+		for _, e := range entities {
+			for i := e.Start; i < e.End; i++ {
+				covered[i] = true
+			}
+		}
+	}
+
+	for _, t := range s.RawTokens(text, ModeHybrid) {
+		word := t.Text
+		if t.Norm != "" {
+			word = t.Norm
+		}
+		tag, ok := s.Dict.Tag(word)
+		if !ok {
+			continue
+		}
+		typ, ok := ner.TypeFromPOS(tag)
+		if !ok || slices.Contains(covered[t.Start:t.End], true) {
+			continue
+		}
+		entities = append(entities, ner.Entity{Text: t.Text, Type: typ, Start: t.Start, End: t.End})
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].Start < entities[j].Start
+	})
+	return entities
+}
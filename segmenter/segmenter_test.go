@@ -2,10 +2,15 @@ package segmenter
 
 import (
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/teatak/seg/crf"
 	"github.com/teatak/seg/dictionary"
+	"github.com/teatak/seg/hmm"
+	"github.com/teatak/seg/ner"
+	"github.com/teatak/seg/zhconv"
 )
 
 func TestCut(t *testing.T) {
@@ -103,3 +108,570 @@ func TestCutCRFForSearch(t *testing.T) {
 		t.Errorf("CutSearch(%q, ModeCRF) = %v, want %v", text, got, expected)
 	}
 }
+
+func TestCut_RareHan(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("𠮷野家", 100, "brand")
+	dict.Add("陈㐀明", 100, "nr")
+	dict.Add("吃饭", 100, "v")
+	dict.Add("广州", 100, "ns")
+	dict.Add("李郎", 100, "nr")
+
+	seg := NewSegmenter(dict)
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"去𠮷野家吃饭", []string{"去", "𠮷野家", "吃饭"}},
+		{"陈㐀明在广州", []string{"陈㐀明", "在", "广州"}},
+		// Compatibility ideographs (U+F92C) fold to the unified 郎; the original text is kept.
+		{"李\uF92C在广州", []string{"李\uF92C", "在", "广州"}},
+	}
+	for _, tt := range tests {
+		got := seg.Cut(tt.input)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Cut(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+
+	// Without normalization a compatibility ideograph is still Han and stays inside the block.
+	dict.Add("李\uF92C", 100, "nr")
+	raw := NewSegmenter(dict)
+	raw.Normalizer = nil
+	if got := raw.Cut("李\uF92C在广州"); !reflect.DeepEqual(got, []string{"李\uF92C", "在", "广州"}) {
+		t.Errorf("Cut without normalizer = %v", got)
+	}
+
+	// Offsets count runes, so a character outside the BMP is one position wide.
+	tokens := seg.Tokens("𠮷野家")
+	if len(tokens) != 1 || tokens[0].Start != 0 || tokens[0].End != 3 {
+		t.Errorf("Tokens(𠮷野家) = %v", tokens)
+	}
+}
+
+func TestEntities_Dictionary(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("刘强东", 100, "nr")
+	dict.Add("京东物流", 100, "nt")
+	dict.Add("的", 1000, "uj")
+
+	seg := NewSegmenter(dict)
+	got := seg.Entities("刘强东的京东物流")
+	expected := []ner.Entity{
+		{Text: "刘强东", Type: ner.PER, Start: 0, End: 3},
+		{Text: "京东物流", Type: ner.ORG, Start: 4, End: 8},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Entities() = %v, want %v", got, expected)
+	}
+
+	// Filters that add or drop tokens must not shift the offsets.
+	seg.Filters = FilterChain{NewPinyinFilter(), PunctuationFilter()}
+	if got := seg.Entities("刘强东的京东物流"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Entities() with filters = %v, want %v", got, expected)
+	}
+}
+
+func TestEntities_ModelInsideWord(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("京东物流", 100, "nt")
+
+	// The model tags only 东物, in the middle of the dictionary word.
+	m := crf.NewModelWithLabels([]string{"O", "B-LOC", "I-LOC"})
+	m.Feats["U02:东"] = map[int]float64{1: 10}
+	m.Feats["U02:物"] = map[int]float64{2: 10}
+	for _, r := range "京流" {
+		m.Feats["U02:"+string(r)] = map[int]float64{0: 10}
+	}
+	seg := NewSegmenter(dict)
+	seg.NERModel = m
+
+	got := seg.Entities("京东物流")
+	want := []ner.Entity{{Text: "东物", Type: ner.LOC, Start: 1, End: 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Entities() = %v, want %v", got, want)
+	}
+}
+
+func TestEntities_ModelAndDictionary(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("京东物流", 100, "nt")
+	dict.Add("杭州", 100, "ns")
+	dict.Add("强东", 1000, "nr")
+	dict.Add("的", 1000, "uj")
+
+	// The model finds only the person; the dictionary adds the organisation and the place.
+	m := crf.NewModelWithLabels([]string{"O", "B-PER", "I-PER"})
+	m.Feats["U02:刘"] = map[int]float64{1: 10}
+	for _, r := range "强东" {
+		m.Feats["U02:"+string(r)] = map[int]float64{2: 10}
+	}
+	for _, r := range "的京物流在杭州" {
+		m.Feats["U02:"+string(r)] = map[int]float64{0: 10}
+	}
+	seg := NewSegmenter(dict)
+	seg.NERModel = m
+
+	if got := seg.Cut("刘强东"); !slices.Contains(got, "强东") {
+		t.Fatalf("Cut(刘强东) = %v, want the dictionary word 强东", got)
+	}
+	// 强东 is a dictionary nr inside the model's 刘强东 and is dropped.
+	got := seg.Entities("刘强东的京东物流在杭州")
+	want := []ner.Entity{
+		{Text: "刘强东", Type: ner.PER, Start: 0, End: 3},
+		{Text: "京东物流", Type: ner.ORG, Start: 4, End: 8},
+		{Text: "杭州", Type: ner.LOC, Start: 9, End: 11},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Entities() = %v, want %v", got, want)
+	}
+}
+
+func TestCut_Rules(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("时代", 100, "")
+	dict.Add("发布", 100, "")
+
+	seg := NewSegmenter(dict)
+	text := "Web3.0时代GPT-4发布"
+	expected := []string{"Web3.0", "时代", "GPT-4", "发布"}
+	if got := seg.Cut(text); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Cut(%q) = %v, want %v", text, got, expected)
+	}
+
+	// A rule match never cuts a longer dictionary word.
+	for _, w := range []string{"一家人", "一次性", "三人行", "一个人", "付清"} {
+		dict.Add(w, 100, "")
+	}
+	for text, want := range map[string][]string{
+		"一家人":   {"一家人"},
+		"一次性付清": {"一次性", "付清"},
+		"三人行":   {"三人行"},
+		"一个人":   {"一个人"},
+		"一千块时代": {"一千块", "时代"},
+	} {
+		if got := seg.Cut(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("Cut(%q) = %v, want %v", text, got, want)
+		}
+	}
+
+	seg.DisableRules = true
+	expected = []string{"Web3", ".", "0", "时代", "GPT", "-", "4", "发布"}
+	if got := seg.Cut(text); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Cut(%q) without rules = %v, want %v", text, got, expected)
+	}
+}
+
+func TestTokens_PreTokenizer(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("联系", 100, "")
+
+	seg := NewSegmenter(dict)
+	if err := seg.PreTokenizer.Register("ORDER", `SO\d{8}`); err != nil {
+		t.Fatal(err)
+	}
+	got := seg.Tokens("联系me@qq.com#周末#@小明 https://a.cn/x?y=1，SO20240501👨‍👩‍👧")
+	expected := []Token{
+		{Text: "联系", Start: 0, End: 2},
+		{Text: "me@qq.com", Type: TypeEmail, Start: 2, End: 11},
+		{Text: "#周末#", Type: TypeHashtag, Start: 11, End: 15},
+		{Text: "@小明", Type: TypeMention, Start: 15, End: 18},
+		{Text: " ", Start: 18, End: 19},
+		{Text: "https://a.cn/x?y=1", Type: TypeURL, Start: 19, End: 37},
+		{Text: "，", Start: 37, End: 38},
+		{Text: "SO20240501", Type: "ORDER", Start: 38, End: 48},
+		{Text: "👨‍👩‍👧", Type: TypeEmoji, Start: 48, End: 53},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Tokens() =\n%v\nwant\n%v", got, expected)
+	}
+}
+
+func TestPreTokenizer_Emoji(t *testing.T) {
+	// Decorative stars stay text; emoji-style symbols and ones with U+FE0F are emoji.
+	got := NewPreTokenizer().Split("★★★☆汉庭⭐❤\uFE0F☕♥")
+	want := []Token{
+		{Text: "⭐", Type: TypeEmoji, Start: 6, End: 7},
+		{Text: "❤\uFE0F", Type: TypeEmoji, Start: 7, End: 9},
+		{Text: "☕", Type: TypeEmoji, Start: 9, End: 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Split() = %v, want %v", got, want)
+	}
+}
+
+func TestTokens_Normalizer(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("发布", 100, "")
+
+	seg := NewSegmenter(dict)
+	got := seg.Tokens("ＧＰＴ－４发布")
+	expected := []Token{
+		{Text: "ＧＰＴ－４", Norm: "GPT-4", Type: "MODEL", Start: 0, End: 5},
+		{Text: "发布", Start: 5, End: 7},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Tokens() = %v, want %v", got, expected)
+	}
+}
+
+func TestTokens_Traditional(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("台北", 100, "ns")
+	dict.Add("酒店", 100, "n")
+	dict.Add("头发", 100, "n")
+
+	seg := NewSegmenter(dict)
+	seg.Converter = zhconv.Default()
+	got := seg.Tokens("臺北酒店")
+	expected := []Token{
+		{Text: "臺北", Norm: "台北", Start: 0, End: 2},
+		{Text: "酒店", Start: 2, End: 4},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Tokens() = %v, want %v", got, expected)
+	}
+
+	// Normalization and conversion combine: the offsets still refer to the original.
+	got = seg.Tokens("１頭髮")
+	expected = []Token{
+		{Text: "１", Norm: "1", Type: "NUMBER", Start: 0, End: 1},
+		{Text: "頭髮", Norm: "头发", Start: 1, End: 3},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Tokens() = %v, want %v", got, expected)
+	}
+}
+
+func TestTokens_Filters(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	for _, w := range []string{"我们", "入住", "北京", "酒店", "宾馆", "Hilton"} {
+		dict.Add(w, 100, "")
+	}
+	seg := NewSegmenter(dict)
+
+	dict.AddSynonyms("酒店", "宾馆")
+	registry := NewFilterRegistry(dict)
+	chain, err := registry.Chain("punct", "stopwords", "lowercase", "synonyms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg.Filters = append(chain, LengthFilter(2, 6))
+
+	got := seg.Tokens("我们入住北京Hilton酒店，很好！")
+	expected := []Token{
+		{Text: "入住", Start: 2, End: 4},
+		{Text: "北京", Start: 4, End: 6},
+		{Text: "hilton", Start: 6, End: 12},
+		{Text: "酒店", Start: 12, End: 14},
+		{Text: "宾馆", Type: TypeSynonym, Start: 12, End: 14},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Tokens() = %v, want %v", got, expected)
+	}
+
+	// Cut goes through the same chain; "hilton" is longer than 5 runes.
+	seg.Filters = append(chain, LengthFilter(2, 5))
+	if got, want := seg.Cut("我们入住北京Hilton酒店"), []string{"入住", "北京", "酒店", "宾馆"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cut() = %v, want %v", got, want)
+	}
+
+	if _, err := registry.Chain("nope"); err == nil {
+		t.Error("expected an error for an unknown filter")
+	}
+}
+
+func TestTokens_Pinyin(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	for _, w := range []string{"如家", "酒店", "银行", "长沙"} {
+		dict.Add(w, 100, "")
+	}
+	seg := NewSegmenter(dict)
+	seg.Filters = FilterChain{PunctuationFilter(), NewPinyinFilter()}
+
+	got := seg.Tokens("长沙如家酒店，ATM")
+	expected := []Token{
+		{Text: "长沙", Start: 0, End: 2},
+		{Text: "changsha", Type: TypePinyin, Start: 0, End: 2},
+		{Text: "cs", Type: TypeInitials, Start: 0, End: 2},
+		{Text: "如家", Start: 2, End: 4},
+		{Text: "rujia", Type: TypePinyin, Start: 2, End: 4},
+		{Text: "rj", Type: TypeInitials, Start: 2, End: 4},
+		{Text: "酒店", Start: 4, End: 6},
+		{Text: "jiudian", Type: TypePinyin, Start: 4, End: 6},
+		{Text: "jd", Type: TypeInitials, Start: 4, End: 6},
+		{Text: "ATM", Start: 7, End: 10},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Tokens() = %v, want %v", got, expected)
+	}
+
+	// The reading follows the word: 行 is hang in 银行.
+	if got, want := seg.Cut("银行"), []string{"银行", "yinhang", "yh"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cut() = %v, want %v", got, want)
+	}
+}
+
+func TestCutSearchExpand(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	for _, w := range []string{"希尔顿", "欢朋", "希尔顿欢朋", "酒店", "七天"} {
+		dict.Add(w, 100, "")
+	}
+	dict.AddSynonyms("7天", "七天")
+	dict.AddSynonyms("希尔顿欢朋", "欢朋", "Hampton")
+	seg := NewSegmenter(dict)
+
+	got := seg.CutSearchExpand("希尔顿欢朋酒店")
+	// Only the whole word is expanded, and 欢朋 is already a sub-word: each spelling once.
+	want := []string{"希尔顿", "欢朋", "希尔顿欢朋", "Hampton", "酒店"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CutSearchExpand() = %v, want %v", got, want)
+	}
+
+	// "7天" is a rule-based QUANTITY token; its synonym takes the same position.
+	tokens := seg.ExpandTokens("住7天")
+	last := tokens[len(tokens)-1]
+	if last.Text != "七天" || last.Type != TypeSynonym || last.Start != 1 || last.End != 3 {
+		t.Errorf("ExpandTokens() = %v, want 七天 at [1, 3)", tokens)
+	}
+
+	// Sub-words of CutSearch carry their own offsets.
+	search := seg.SearchTokens("希尔顿欢朋")
+	if search[1].Text != "欢朋" || search[1].Start != 3 || search[1].End != 5 {
+		t.Errorf("SearchTokens() = %v", search)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	for _, w := range []string{"南京市", "长江大桥", "南京", "市长", "长江", "大桥"} {
+		dict.Add(w, 100, "")
+	}
+	dict.Layers["长江大桥"] = "user"
+	seg := NewSegmenter(dict)
+
+	e := seg.Explain("南京市长江大桥", ModeDAG)
+	var words []string
+	for _, w := range e.Words {
+		words = append(words, w.Text+"/"+w.Source)
+	}
+	if want := []string{"南京市/dict", "长江大桥/dict"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("Explain() words = %v, want %v", words, want)
+	}
+	if e.Words[1].Layer != "user" {
+		t.Errorf("Layer = %q, want user", e.Words[1].Layer)
+	}
+	// The lattice has the competing 南京 / 市长 path, not chosen and scored lower.
+	var best, rival Candidate
+	for _, c := range e.Blocks[0].Lattice {
+		switch c.Word {
+		case "南京市":
+			best = c
+		case "南京":
+			rival = c
+		}
+	}
+	if !best.Chosen || rival.Chosen || rival.Score >= best.Score {
+		t.Errorf("lattice: 南京市 %+v, 南京 %+v", best, rival)
+	}
+
+	// Hybrid: single characters of the route go to the CRF model, with their tag scores.
+	m := crf.NewModel()
+	m.Feats["U02:程"] = map[int]float64{crf.TagB: 10.0}
+	m.Feats["U02:序"] = map[int]float64{crf.TagM: 10.0}
+	m.Feats["U02:员"] = map[int]float64{crf.TagE: 10.0}
+	seg.CRFModel = m
+	text := "访问www.baidu.com的程序员在南京市"
+	e = seg.Explain(text, ModeHybrid)
+	var got []string
+	sources := make(map[string]string)
+	for _, w := range e.Words {
+		got = append(got, w.Text)
+		sources[w.Text] = w.Source
+	}
+	if want := seg.Cut(text, ModeHybrid); !reflect.DeepEqual(got, want) {
+		t.Errorf("Explain() words = %v, Cut() = %v", got, want)
+	}
+	if sources["程序员"] != SourceCRF || sources["www.baidu.com"] != SourceRule || sources["南京市"] != SourceDict {
+		t.Errorf("sources = %v", sources)
+	}
+	for _, b := range e.Blocks {
+		for _, c := range b.CRF {
+			if c.Char == "序" && (c.Tag != "M" || c.Scores["M"] != 10) {
+				t.Errorf("CRF score of 序 = %+v", c)
+			}
+		}
+	}
+}
+
+func TestExplain_StrategyDecode(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("丁戊", 100, "")
+	seg := NewSegmenter(dict)
+	m := crf.NewModel()
+	m.Feats["U02:甲"] = map[int]float64{crf.TagB: 10.0}
+	m.Feats["U02:乙"] = map[int]float64{crf.TagM: 10.0, crf.TagE: 8.0}
+	// 丙 is a word of its own at the end of a text, the end of 甲乙丙 before 丁.
+	m.Feats["U02:丙"] = map[int]float64{crf.TagS: 5.0}
+	m.Feats["U03:丁"] = map[int]float64{crf.TagE: 10.0}
+	m.Feats["U02:丁"] = map[int]float64{crf.TagB: 10.0}
+	m.Feats["U02:戊"] = map[int]float64{crf.TagE: 10.0}
+	seg.CRFModel = m
+	seg.Strategy = JointLattice{Weight: 1}
+
+	// The joint strategy decodes the whole block; decoding 甲乙丙 alone would tag 丙 S.
+	e := seg.Explain("甲乙丙丁戊", ModeHybrid)
+	var words, tags []string
+	for _, w := range e.Words {
+		words = append(words, w.Text+"/"+w.Source)
+	}
+	for _, c := range e.Blocks[0].CRF {
+		tags = append(tags, c.Tag)
+	}
+	if want := []string{"甲乙丙/crf", "丁戊/dict"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("Explain() words = %v, want %v", words, want)
+	}
+	if want := []string{"B", "M", "E", "B", "E"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("Explain() tags = %v, want %v", tags, want)
+	}
+}
+
+func TestCutHybrid_Strategies(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("将对", 50, "") // a wrong dictionary hit in 将对手
+	dict.Add("对手", 10, "")
+	seg := NewSegmenter(dict)
+	m := crf.NewModel()
+	m.Feats["U02:将"] = map[int]float64{crf.TagS: 10.0}
+	m.Feats["U02:对"] = map[int]float64{crf.TagB: 10.0}
+	m.Feats["U02:手"] = map[int]float64{crf.TagE: 10.0}
+	seg.CRFModel = m
+
+	tests := []struct {
+		strategy Strategy
+		expected []string
+	}{
+		{nil, []string{"将对", "手"}},
+		{TrustLength{}, []string{"将对", "手"}},
+		{TrustFrequency{MinFreq: 100}, []string{"将", "对手"}},
+		{CRFArbitration{MinProb: 0.5}, []string{"将", "对手"}},
+		{JointLattice{Weight: 1}, []string{"将", "对手"}},
+	}
+	for _, tt := range tests {
+		seg.Strategy = tt.strategy
+		if got := seg.Cut("将对手", ModeHybrid); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Cut() with %T = %v, want %v", tt.strategy, got, tt.expected)
+		}
+		e := seg.Explain("将对手", ModeHybrid)
+		var words []string
+		for _, w := range e.Words {
+			words = append(words, w.Text)
+		}
+		if !reflect.DeepEqual(words, tt.expected) {
+			t.Errorf("Explain() with %T = %v, want %v", tt.strategy, words, tt.expected)
+		}
+		// The tags shown are the ones the CRF words were cut by.
+		tags := make(map[int]string)
+		for _, c := range e.Blocks[0].CRF {
+			tags[c.Pos] = c.Tag
+		}
+		for _, w := range e.Words {
+			if w.Source != SourceCRF {
+				continue
+			}
+			shape := "S"
+			if w.End-w.Start > 1 {
+				shape = "B" + strings.Repeat("M", w.End-w.Start-2) + "E"
+			}
+			var got string
+			for i := w.Start; i < w.End; i++ {
+				got += tags[i]
+			}
+			if got != shape {
+				t.Errorf("Explain() with %T: tags of %s = %q, want %q", tt.strategy, w.Text, got, shape)
+			}
+		}
+	}
+
+	if s, err := NewStrategy("joint"); err != nil || s != (JointLattice{Weight: 1}) {
+		t.Errorf("NewStrategy(joint) = %v, %v", s, err)
+	}
+	if _, err := NewStrategy("nope"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	dict := dictionary.NewDictionary()
+	dict.Add("如家", 100, "")
+	dict.Add("酒店", 100, "")
+
+	if _, err := New(WithAlgorithm("nope")); err == nil {
+		t.Error("New(WithAlgorithm(nope)) should fail")
+	}
+	seg, err := New(WithDictionary(dict), WithAlgorithm("dag"), WithNormalizer(nil), WithFilters(PunctuationFilter()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seg.Normalizer != nil {
+		t.Error("WithNormalizer(nil) should disable normalization")
+	}
+	want := []string{"如家", "酒店"}
+	if got := seg.Cut("如家，酒店"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cut() = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterTokenizer(t *testing.T) {
+	// Cuts every character apart, whatever the dictionary says.
+	RegisterTokenizer("test-chars", TokenizerFunc(func(s *Segmenter, runes []rune) []Piece {
+		var pieces []Piece
+		for _, r := range runes {
+			pieces = append(pieces, Piece{Word: string(r), Source: SourceChar})
+		}
+		return pieces
+	}))
+	if !slices.Contains(TokenizerNames(), "test-chars") {
+		t.Errorf("TokenizerNames() = %v, missing test-chars", TokenizerNames())
+	}
+
+	dict := dictionary.NewDictionary()
+	dict.Add("如家", 100, "")
+	seg, err := New(WithDictionary(dict), WithAlgorithm("test-chars"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"如", "家", "2024"}
+	if got := seg.Cut("如家2024"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cut() = %v, want %v", got, want)
+	}
+	if e := seg.Explain("如家"); e.Words[0].Source != SourceChar || e.Mode != "test-chars" {
+		t.Errorf("Explain() source = %q, mode = %q, want %q, test-chars", e.Words[0].Source, e.Mode, SourceChar)
+	}
+}
+
+func TestTokenizer_HMM(t *testing.T) {
+	var sents []crf.Sentence
+	for _, line := range [][]string{
+		{"汉庭", "酒店", "北京", "店"},
+		{"全季", "酒店", "上海", "店"},
+		{"汉庭", "快捷", "酒店"},
+	} {
+		sents = append(sents, crf.SentenceFromWords(line))
+	}
+	dict := dictionary.NewDictionary()
+	dict.Add("酒店", 100, "")
+	seg, err := New(WithDictionary(dict), WithHMM(hmm.Train(sents)), WithAlgorithm("hmm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 酒店 comes from the dictionary, 汉庭 and 北京 from the HMM.
+	want := []string{"汉庭", "酒店", "北京", "店"}
+	if got := seg.Cut("汉庭酒店北京店"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cut() = %v, want %v", got, want)
+	}
+	// Without a CRF model the hybrid tokenizer uses the HMM.
+	if got := seg.Cut("汉庭酒店北京店", ModeHybrid); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cut(hybrid) = %v, want %v", got, want)
+	}
+}
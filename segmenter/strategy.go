@@ -0,0 +1,223 @@
+package segmenter
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/teatak/seg/crf"
+)
+
+// Piece is a word chosen by a Strategy and where it came from (SourceDict, SourceCRF, ...).
+type Piece struct {
+	Word   string
+	Source string
+}
+
+// Strategy merges the dictionary and the CRF model in ModeHybrid. Cut segments a block of
+// text and returns its words in order: a run of Han characters, letters and digits, or a
+// run of other characters such as punctuation, spaces and symbols, which a strategy must
+// also cover. The CRF model of the segmenter is loaded.
+type Strategy interface {
+	Cut(s *Segmenter, runes []rune) []Piece
+}
+
+// TrustLength keeps every word of two or more characters on the best DAG route and sends the
+// runs of single characters to the CRF model. It is the default strategy.
+type TrustLength struct{}
+
+// Cut implements Strategy.
+func (TrustLength) Cut(s *Segmenter, runes []rune) []Piece {
+	return s.trustRoute(runes, func(start, end int) bool { return true }, s.crfPieces)
+}
+
+// TrustFrequency is TrustLength for dictionary words of at least MinFreq: a rare dictionary
+// hit such as 和机 is cut again by the CRF model together with the characters around it.
+type TrustFrequency struct {
+	MinFreq float64
+}
+
+// Cut implements Strategy.
+func (t TrustFrequency) Cut(s *Segmenter, runes []rune) []Piece {
+	return s.trustRoute(runes, func(start, end int) bool {
+		freq, _ := s.Dict.Frequency(string(runes[start:end]))
+		return freq >= t.MinFreq
+	}, s.crfPieces)
+}
+
+// CRFArbitration lets the CRF model veto dictionary words: a word of the DAG route is kept
+// when the per-character probability of its tags (the geometric mean of the CRF marginals,
+// e.g. B then E) is at least MinProb; otherwise it is cut again by the model.
+type CRFArbitration struct {
+	MinProb float64
+}
+
+// Cut implements Strategy.
+func (a CRFArbitration) Cut(s *Segmenter, runes []rune) []Piece {
+	marginals := s.CRFModel.Marginals(runes)
+	return s.trustRoute(runes, func(start, end int) bool {
+		return math.Exp(wordLogProb(marginals, start, end)/float64(end-start)) >= a.MinProb
+	}, s.crfPieces)
+}
+
+// JointLattice finds the best path through a lattice of the dictionary candidates and the
+// words of the CRF model, scoring a word by its dictionary log-probability plus Weight times
+// the log-probability of its tags under the CRF marginals. Unlike the trust strategies, a
+// dictionary word can lose to an overlapping CRF word and the other way round.
+type JointLattice struct {
+	Weight float64
+}
+
+// Cut implements Strategy.
+func (j JointLattice) Cut(s *Segmenter, runes []rune) []Piece {
+	n := len(runes)
+	marginals := s.CRFModel.Marginals(runes)
+	candidates := s.buildDAG(runes)
+	fromCRF := make(map[[2]int]bool)
+	pos := 0
+	for _, p := range s.crfPieces(runes) {
+		end := pos + len([]rune(p.Word))
+		fromCRF[[2]int{pos, end}] = true
+		if !slices.Contains(candidates[pos], end-1) {
+			candidates[pos] = append(candidates[pos], end-1)
+		}
+		pos = end
+	}
+
+	route := make([]routeNode, n+1)
+	for i := n - 1; i >= 0; i-- {
+		route[i] = routeNode{prob: math.Inf(-1), end: i}
+		for _, end := range candidates[i] {
+			word := string(runes[i : end+1])
+			prob := s.Dict.LogProbability(word) + j.Weight*wordLogProb(marginals, i, end+1) + route[end+1].prob
+			if prob > route[i].prob {
+				route[i] = routeNode{prob: prob, end: end}
+			}
+		}
+	}
+
+	var pieces []Piece
+	for i := 0; i < n; i = route[i].end + 1 {
+		word := string(runes[i : route[i].end+1])
+		source := s.dagSource(word)
+		if source != SourceDict && fromCRF[[2]int{i, route[i].end + 1}] {
+			source = SourceCRF
+		}
+		pieces = append(pieces, Piece{Word: word, Source: source})
+	}
+	return pieces
+}
+
+// strategies builds the strategies selectable by name, with their default settings.
+var strategies = map[string]func() Strategy{
+	"length":    func() Strategy { return TrustLength{} },
+	"frequency": func() Strategy { return TrustFrequency{MinFreq: 5} },
+	"marginal":  func() Strategy { return CRFArbitration{MinProb: 0.5} },
+	"joint":     func() Strategy { return JointLattice{Weight: 1} },
+}
+
+// NewStrategy returns the named strategy with its default settings: length (the default),
+// frequency, marginal or joint.
+func NewStrategy(name string) (Strategy, error) {
+	build, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q (available: %s)", name, strings.Join(StrategyNames(), ", "))
+	}
+	return build(), nil
+}
+
+// StrategyNames returns the names accepted by NewStrategy in sorted order.
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// strategy returns the hybrid strategy of the segmenter, TrustLength when none is set.
+func (s *Segmenter) strategy() Strategy {
+	if s.Strategy != nil {
+		return s.Strategy
+	}
+	return TrustLength{}
+}
+
+// trustRoute keeps the words of the best DAG route that trust accepts, as well as runs of
+// letters and digits, and cuts the other characters with recut (the CRF model or the HMM).
+// Single characters are never trusted.
+func (s *Segmenter) trustRoute(runes []rune, trust func(start, end int) bool, recut func([]rune) []Piece) []Piece {
+	var pieces []Piece
+	bufStart := -1
+	flush := func(end int) {
+		if bufStart < 0 {
+			return
+		}
+		pieces = append(pieces, recut(runes[bufStart:end])...)
+		bufStart = -1
+	}
+	for _, span := range s.routeSpans(runes) {
+		word := string(runes[span[0]:span[1]])
+		source := s.dagSource(word)
+		if span[1]-span[0] > 1 && (source == SourceAlnum || trust(span[0], span[1])) {
+			flush(span[0])
+			pieces = append(pieces, Piece{Word: word, Source: source})
+		} else if bufStart < 0 {
+			bufStart = span[0]
+		}
+	}
+	flush(len(runes))
+	return pieces
+}
+
+// routeSpans returns the [start, end) spans of the words on the best DAG route.
+func (s *Segmenter) routeSpans(runes []rune) [][2]int {
+	route := s.bestRoute(runes, s.buildDAG(runes))
+	var spans [][2]int
+	for i := 0; i < len(runes); i = route[i].end + 1 {
+		spans = append(spans, [2]int{i, route[i].end + 1})
+	}
+	return spans
+}
+
+// dagSource tells whether a word of the DAG is a dictionary word, a run of letters and
+// digits or an unknown character.
+func (s *Segmenter) dagSource(word string) string {
+	switch {
+	case s.Dict.Contains(word):
+		return SourceDict
+	case isAlphaNum([]rune(word)[0]):
+		return SourceAlnum
+	default:
+		return SourceChar
+	}
+}
+
+// minMarginal bounds the CRF probability of a tag so that an unlikely word costs about as
+// much as an unknown dictionary word instead of -Inf.
+const minMarginal = 1e-9
+
+// wordLogProb returns the log-probability of runes [start, end) being one word under the CRF
+// marginals: S for one character, B M... E otherwise.
+func wordLogProb(marginals [][]float64, start, end int) float64 {
+	tag := func(i int) int {
+		switch {
+		case end-start == 1:
+			return crf.TagS
+		case i == start:
+			return crf.TagB
+		case i == end-1:
+			return crf.TagE
+		default:
+			return crf.TagM
+		}
+	}
+	sum := 0.0
+	for i := start; i < end; i++ {
+		sum += math.Log(math.Max(marginals[i][tag(i)], minMarginal))
+	}
+	return sum
+}
@@ -0,0 +1,207 @@
+package segmenter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SourceHMM marks a word cut by the HMM, see HMMTokenizer.
+const SourceHMM = "hmm"
+
+// Tokenizer is a segmentation algorithm. Tokenize cuts a block of text, a run of word
+// characters or of other characters, into words in order; the pre-tokenizer, rules,
+// normalization and filters around it are handled by the Segmenter. Algorithms are
+// registered by name with RegisterTokenizer and selected with TokenizerByName. A tokenizer
+// with a Name() string method is reported under that name, e.g. by Explain.
+type Tokenizer interface {
+	Tokenize(s *Segmenter, runes []rune) []Piece
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(s *Segmenter, runes []rune) []Piece
+
+// Tokenize calls f(s, runes).
+func (f TokenizerFunc) Tokenize(s *Segmenter, runes []rune) []Piece {
+	return f(s, runes)
+}
+
+// DAGTokenizer takes the maximum probability path through the dictionary words.
+type DAGTokenizer struct{}
+
+// Name returns the registered name.
+func (DAGTokenizer) Name() string { return ModeDAG.String() }
+
+// Tokenize implements Tokenizer.
+func (DAGTokenizer) Tokenize(s *Segmenter, runes []rune) []Piece {
+	var pieces []Piece
+	for _, span := range s.routeSpans(runes) {
+		word := string(runes[span[0]:span[1]])
+		pieces = append(pieces, Piece{Word: word, Source: s.dagSource(word)})
+	}
+	return pieces
+}
+
+// CRFTokenizer cuts with the CRF model alone; without a model it falls back to the DAG.
+type CRFTokenizer struct{}
+
+// Name returns the registered name.
+func (CRFTokenizer) Name() string { return ModeCRF.String() }
+
+// Tokenize implements Tokenizer.
+func (CRFTokenizer) Tokenize(s *Segmenter, runes []rune) []Piece {
+	if s.CRFModel == nil {
+		return DAGTokenizer{}.Tokenize(s, runes)
+	}
+	return s.crfPieces(runes)
+}
+
+// HybridTokenizer merges the dictionary and the CRF model with the Strategy of the segmenter.
+// Without a CRF model it uses the HMM when there is one, and the DAG otherwise.
+type HybridTokenizer struct{}
+
+// Name returns the registered name.
+func (HybridTokenizer) Name() string { return ModeHybrid.String() }
+
+// Tokenize implements Tokenizer.
+func (HybridTokenizer) Tokenize(s *Segmenter, runes []rune) []Piece {
+	switch {
+	case s.CRFModel != nil:
+		return s.strategy().Cut(s, runes)
+	case s.HMMModel != nil:
+		return HMMTokenizer{}.Tokenize(s, runes)
+	default:
+		return DAGTokenizer{}.Tokenize(s, runes)
+	}
+}
+
+// HMMTokenizer keeps the words of two or more characters on the DAG route and cuts the runs
+// of single characters with the HMM, like jieba; without an HMM it falls back to the DAG.
+type HMMTokenizer struct{}
+
+// Name returns the registered name.
+func (HMMTokenizer) Name() string { return "hmm" }
+
+// Tokenize implements Tokenizer.
+func (HMMTokenizer) Tokenize(s *Segmenter, runes []rune) []Piece {
+	if s.HMMModel == nil {
+		return DAGTokenizer{}.Tokenize(s, runes)
+	}
+	return s.trustRoute(runes, func(start, end int) bool { return true }, s.hmmPieces)
+}
+
+// Tokenizer returns the built-in tokenizer of the mode.
+func (m Mode) Tokenizer() Tokenizer {
+	switch m {
+	case ModeCRF:
+		return CRFTokenizer{}
+	case ModeHybrid:
+		return HybridTokenizer{}
+	default:
+		return DAGTokenizer{}
+	}
+}
+
+var (
+	tokenizersMu sync.RWMutex
+	tokenizers   = map[string]Tokenizer{
+		ModeDAG.String():    DAGTokenizer{},
+		ModeCRF.String():    CRFTokenizer{},
+		ModeHybrid.String(): HybridTokenizer{},
+		"hmm":               HMMTokenizer{},
+	}
+)
+
+// namedTokenizer is a tokenizer registered under a name of its own.
+type namedTokenizer struct {
+	Tokenizer
+	name string
+}
+
+// Name returns the registered name.
+func (t namedTokenizer) Name() string { return t.name }
+
+// RegisterTokenizer makes a tokenizer selectable by name in TokenizerByName, and so by the
+// -mode flag of the CLI and the algorithm field of the API. TokenizerByName returns it under
+// that name. It panics if the name is taken.
+func RegisterTokenizer(name string, t Tokenizer) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+	if t == nil {
+		panic("segmenter: RegisterTokenizer of nil tokenizer " + name)
+	}
+	if _, dup := tokenizers[name]; dup {
+		panic("segmenter: RegisterTokenizer called twice for " + name)
+	}
+	tokenizers[name] = namedTokenizer{Tokenizer: t, name: name}
+}
+
+// TokenizerByName returns the registered tokenizer: dag, crf, hybrid, hmm or a custom one.
+func TokenizerByName(name string) (Tokenizer, error) {
+	tokenizersMu.RLock()
+	defer tokenizersMu.RUnlock()
+	t, ok := tokenizers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q (available: %s)", name, strings.Join(tokenizerNames(), ", "))
+	}
+	return t, nil
+}
+
+// TokenizerNames returns the registered tokenizer names in sorted order.
+func TokenizerNames() []string {
+	tokenizersMu.RLock()
+	defer tokenizersMu.RUnlock()
+	return tokenizerNames()
+}
+
+func tokenizerNames() []string {
+	names := make([]string, 0, len(tokenizers))
+	for name := range tokenizers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tokenizer resolves the algorithm of a call: the mode when one is given, then the
+// Tokenizer of the segmenter, then the DAG.
+func (s *Segmenter) tokenizer(modes []Mode) Tokenizer {
+	switch {
+	case len(modes) > 0:
+		return modes[0].Tokenizer()
+	case s.Tokenizer != nil:
+		return s.Tokenizer
+	default:
+		return DAGTokenizer{}
+	}
+}
+
+// tokenizerName names tok for an Explanation: its Name when it has one, else its Go type.
+func tokenizerName(tok Tokenizer) string {
+	if n, ok := tok.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", tok)
+}
+
+// crfPieces cuts runes with the CRF model.
+func (s *Segmenter) crfPieces(runes []rune) []Piece {
+	return pieces(s.decodeCRFBlock(runes), SourceCRF)
+}
+
+// hmmPieces cuts runes with the HMM.
+func (s *Segmenter) hmmPieces(runes []rune) []Piece {
+	if len(runes) == 0 {
+		return nil
+	}
+	return pieces(crfWords(runes, s.HMMModel.Decode(runes)), SourceHMM)
+}
+
+func pieces(words []string, source string) []Piece {
+	result := make([]Piece, len(words))
+	for i, w := range words {
+		result[i] = Piece{Word: w, Source: source}
+	}
+	return result
+}
@@ -1,5 +1,7 @@
 package segmenter
 
+import "unicode"
+
 type textBlock struct {
 	runes          []rune
 	isPureAlphaNum bool
@@ -52,6 +54,8 @@ func isAlphaNum(r rune) bool {
 	return false
 }
 
+// isCJK reports whether r is a Han character, including Extension A-G outside the BMP
+// (㐀, 𠮷) and compatibility ideographs, which rare surnames and place names rely on.
 func isCJK(r rune) bool {
-	return r >= 0x4E00 && r <= 0x9FFF
+	return unicode.Is(unicode.Han, r)
 }
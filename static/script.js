@@ -41,6 +41,79 @@ async function runSegment() {
     }
 }
 
+async function runExplain() {
+    const text = document.getElementById('inputText').value.trim();
+    if (!text) return;
+
+    const algorithm = document.querySelector('input[name="algorithm"]:checked').value;
+    try {
+        const res = await fetch(`${API_HOST}/explain`, {
+            method: 'POST',
+            body: JSON.stringify({ text, algorithm })
+        });
+        const data = await res.json();
+        renderExplain(data.explanation);
+        document.getElementById('explainArea').classList.remove('hidden');
+        log(`Explained "${text.substring(0, 10)}..." (${data.explanation.mode})`);
+    } catch (e) {
+        console.error(e);
+        log(`Error: ${e.message}`, 'error');
+    }
+}
+
+const SOURCE_STYLES = {
+    dict: 'bg-indigo-100 text-indigo-700',
+    crf: 'bg-amber-100 text-amber-700',
+    hmm: 'bg-orange-100 text-orange-700',
+    rule: 'bg-green-100 text-green-700',
+    alnum: 'bg-slate-100 text-slate-600',
+    char: 'bg-red-100 text-red-700',
+};
+
+function escapeHTML(s) {
+    return s.replace(/[&<>"']/g, c => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' })[c]);
+}
+
+function renderExplain(ex) {
+    const container = document.getElementById('explainContainer');
+    let html = '';
+    if (ex.norm) {
+        html += `<p class="text-xs text-slate-500">归一化文本: <span class="font-mono">${escapeHTML(ex.norm)}</span></p>`;
+    }
+
+    // Words with source, layer and probability
+    html += '<div class="flex flex-wrap gap-2">';
+    for (const w of ex.words || []) {
+        const style = SOURCE_STYLES[w.source] || 'bg-slate-100 text-slate-600';
+        const detail = [w.source, w.type, w.layer, w.source === 'rule' ? '' : w.log_prob.toFixed(2)].filter(Boolean).join(' · ');
+        html += `<div class="px-3 py-1.5 rounded-lg ${style}"><div class="font-semibold">${escapeHTML(w.text)}</div><div class="text-[10px] font-mono opacity-80">${escapeHTML(detail)}</div></div>`;
+    }
+    html += '</div>';
+
+    for (const b of ex.blocks || []) {
+        if (!b.lattice && !b.crf) continue;
+        html += `<div class="border border-slate-100 rounded-xl p-4"><div class="text-xs font-bold text-slate-500 mb-2">${escapeHTML(b.text)} <span class="font-mono font-normal">[${b.start}, ${b.end}) ${b.method}</span></div>`;
+        if (b.lattice) {
+            html += '<table class="w-full text-xs font-mono mb-3"><tr class="text-slate-400 text-left"><th>词</th><th>位置</th><th>词典</th><th>log P</th><th>路径得分</th></tr>';
+            for (const c of b.lattice) {
+                const cls = c.chosen ? 'bg-indigo-50 text-indigo-700 font-bold' : 'text-slate-600';
+                html += `<tr class="${cls}"><td>${escapeHTML(c.word)}</td><td>${c.start}-${c.end}</td><td>${c.in_dict ? '✓' : ''}</td><td>${c.log_prob.toFixed(2)}</td><td>${c.score.toFixed(2)}</td></tr>`;
+            }
+            html += '</table>';
+        }
+        if (b.crf) {
+            html += '<div class="flex flex-wrap gap-2">';
+            for (const c of b.crf) {
+                const scores = Object.entries(c.scores).map(([t, v]) => `${t}:${v.toFixed(1)}`).join(' ');
+                html += `<div class="px-2 py-1 rounded bg-amber-50 text-center"><div class="font-semibold">${escapeHTML(c.char)}<sub class="text-amber-700 ml-0.5">${c.tag}</sub></div><div class="text-[10px] font-mono text-slate-500">${scores}</div></div>`;
+            }
+            html += '</div>';
+        }
+        html += '</div>';
+    }
+    container.innerHTML = html;
+}
+
 function initInteractiveEditor(tokens) {
     currentChars = [];
     currentSplits = [];
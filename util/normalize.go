@@ -0,0 +1,139 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Normalizer folds text before segmentation: full-width to half-width, compatibility
+// characters to their plain form (an NFKC-style subset, including the CJK compatibility
+// ideographs) and, optionally, case.
+type Normalizer struct {
+	Width     bool // ＧＰＴ－４ -> GPT-4, ideographic space -> space, ￥ -> ¥; keeps ，：（）...
+	Compat    bool // ① -> 1, ² -> 2, Ⅻ -> XII, ﬁ -> fi, ™ -> TM, ㎏ -> kg, ｶ -> カ, U+F91F -> 蘭 U+862D
+	Lowercase bool // ABC -> abc
+}
+
+// NewNormalizer returns a normalizer with width and compatibility folding enabled.
+func NewNormalizer() *Normalizer {
+	return &Normalizer{Width: true, Compat: true}
+}
+
+// Normalized is a normalized string with a mapping back to the original.
+type Normalized struct {
+	Text string
+	// Offsets[i] is the rune index in the original string of normalized rune i.
+	// A final entry holds the original rune count, so Offsets[end] maps an exclusive end.
+	Offsets []int
+}
+
+// Original maps the normalized rune span [start, end) to the original rune span.
+func (n Normalized) Original(start, end int) (int, int) {
+	return n.Offsets[start], n.Offsets[end]
+}
+
+// Normalize folds s. Characters may expand (ﬁ -> fi) or be dropped (zero-width space).
+func (n *Normalizer) Normalize(s string) Normalized {
+	var out []rune
+	var offsets []int
+	i := 0
+	for _, r := range s {
+		for _, f := range n.fold(r) {
+			if n.Lowercase {
+				f = unicode.ToLower(f)
+			}
+			out = append(out, f)
+			offsets = append(offsets, i)
+		}
+		i++
+	}
+	offsets = append(offsets, i)
+	return Normalized{Text: string(out), Offsets: offsets}
+}
+
+func (n *Normalizer) fold(r rune) []rune {
+	switch r {
+	case 0x200B, 0xFEFF: // zero-width space, BOM
+		return nil
+	}
+	if n.Width {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E && !strings.ContainsRune(cjkPunctuation, r):
+			return []rune{r - 0xFEE0}
+		case r == 0x3000 || r == 0x00A0 || (r >= 0x2000 && r <= 0x200A):
+			return []rune{' '}
+		case r >= 0xFFE0 && r <= 0xFFE6:
+			return []rune{[]rune("¢£¬¯¦¥₩")[r-0xFFE0]}
+		}
+	}
+	if n.Compat {
+		if f, ok := compatFold(r); ok {
+			return f
+		}
+	}
+	return []rune{r}
+}
+
+// cjkPunctuation are full-width forms that Chinese text uses as its own punctuation;
+// width folding keeps them so that "，" still ends a URL and "１，２" is not a number.
+const cjkPunctuation = "！＂＇（），：；？［］｛｝～"
+
+// halfwidthKana lists the full-width forms of U+FF61..U+FF9F.
+var halfwidthKana = []rune("。「」、・ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン゙゚")
+
+// compatIdeographs lists the unified forms of the CJK compatibility ideographs U+F900..U+FAD9;
+// the few unified ideographs of the block map to themselves.
+var compatIdeographs = []rune("" +
+	"豈更車賈滑串句龜龜契金喇奈懶癩羅蘿螺裸邏樂洛烙珞落酪駱亂卵欄爛蘭鸞嵐濫藍襤拉臘蠟" +
+	"廊朗浪狼郎來冷勞擄櫓爐盧老蘆虜路露魯鷺碌祿綠菉錄鹿論壟弄籠聾牢磊賂雷壘屢樓淚漏累" +
+	"縷陋勒肋凜凌稜綾菱陵讀拏樂諾丹寧怒率異北磻便復不泌數索參塞省葉說殺辰沈拾若掠略亮" +
+	"兩凉梁糧良諒量勵呂女廬旅濾礪閭驪麗黎力曆歷轢年憐戀撚漣煉璉秊練聯輦蓮連鍊列劣咽烈" +
+	"裂說廉念捻殮簾獵令囹寧嶺怜玲瑩羚聆鈴零靈領例禮醴隸惡了僚寮尿料樂燎療蓼遼龍暈阮劉" +
+	"杻柳流溜琉留硫紐類六戮陸倫崙淪輪律慄栗率隆利吏履易李梨泥理痢罹裏裡里離匿溺吝燐璘" +
+	"藺隣鱗麟林淋臨立笠粒狀炙識什茶刺切度拓糖宅洞暴輻行降見廓兀嗀﨎﨏塚﨑晴﨓﨔凞猪益" +
+	"礼神祥福靖精羽﨟蘒﨡諸﨣﨤逸都﨧﨨﨩飯飼館鶴郞隷侮僧免勉勤卑喝嘆器塀墨層屮悔慨憎" +
+	"懲敏既暑梅海渚漢煮爫琢碑社祉祈祐祖祝禍禎穀突節練縉繁署者臭艹艹著褐視謁謹賓贈辶逸" +
+	"難響頻恵𤋮舘﩮﩯並况全侀充冀勇勺喝啕喙嗢塚墳奄奔婢嬨廒廙彩徭惘慎愈憎慠懲戴揄搜摒" +
+	"敖晴朗望杖歹殺流滛滋漢瀞煮瞧爵犯猪瑱甆画瘝瘟益盛直睊着磌窱節类絛練缾者荒華蝹襁覆" +
+	"視調諸請謁諾諭謹變贈輸遲醙鉶陼難靖韛響頋頻鬒龜𢡊𢡄𣏕㮝䀘䀹𥉉𥳐𧻓齃龎")
+
+var romanNumerals = []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+
+var compatSymbols = map[rune]string{
+	'ﬀ': "ff", 'ﬁ': "fi", 'ﬂ': "fl", 'ﬃ': "ffi", 'ﬄ': "ffl",
+	'™': "TM", '℃': "°C", '℉': "°F", '№': "No",
+	'㎡': "m2", '㎏': "kg", '㎎': "mg", '㎞': "km", '㎝': "cm", '㎜': "mm", '㏄': "cc", '㎖': "ml",
+	'¹': "1", '²': "2", '³': "3", '⓪': "0", '⁰': "0",
+}
+
+func compatFold(r rune) ([]rune, bool) {
+	switch {
+	case r >= 0x2460 && r <= 0x2473: // ①..⑳
+		return []rune(strconv.Itoa(int(r-0x2460) + 1)), true
+	case r >= 0x2474 && r <= 0x2487: // ⑴..⒇
+		return []rune("(" + strconv.Itoa(int(r-0x2474)+1) + ")"), true
+	case r >= 0x2488 && r <= 0x249B: // ⒈..⒛
+		return []rune(strconv.Itoa(int(r-0x2488)+1) + "."), true
+	case r >= 0x24B6 && r <= 0x24CF: // Ⓐ..Ⓩ
+		return []rune{'A' + r - 0x24B6}, true
+	case r >= 0x24D0 && r <= 0x24E9: // ⓐ..ⓩ
+		return []rune{'a' + r - 0x24D0}, true
+	case r >= 0x2074 && r <= 0x2079: // ⁴..⁹
+		return []rune{'4' + r - 0x2074}, true
+	case r >= 0x2080 && r <= 0x2089: // ₀..₉
+		return []rune{'0' + r - 0x2080}, true
+	case r >= 0x2160 && r <= 0x216B: // Ⅰ..Ⅻ
+		return []rune(romanNumerals[r-0x2160]), true
+	case r >= 0x2170 && r <= 0x217B: // ⅰ..ⅻ
+		return []rune(strings.ToLower(romanNumerals[r-0x2170])), true
+	case r >= 0xFF61 && r <= 0xFF9F: // half-width katakana
+		return []rune{halfwidthKana[r-0xFF61]}, true
+	case r >= 0xF900 && r < 0xF900+rune(len(compatIdeographs)): // CJK compatibility ideographs
+		return []rune{compatIdeographs[r-0xF900]}, true
+	}
+	if s, ok := compatSymbols[r]; ok {
+		return []rune(s), true
+	}
+	return nil, false
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	n := NewNormalizer()
+	n.Lowercase = true
+	got := n.Normalize("ＧＰＴ－４，ﬁ​①")
+	if got.Text != "gpt-4，fi1" {
+		t.Errorf("Text = %q", got.Text)
+	}
+	// ﬁ expands to two runes of original rune 6; the zero-width space (7) is dropped.
+	want := []int{0, 1, 2, 3, 4, 5, 6, 6, 8, 9}
+	if !reflect.DeepEqual(got.Offsets, want) {
+		t.Errorf("Offsets = %v, want %v", got.Offsets, want)
+	}
+	if start, end := got.Original(0, 5); start != 0 || end != 5 {
+		t.Errorf("Original(0, 5) = %d, %d", start, end)
+	}
+
+	// Compatibility ideographs fold to the unified ones; 﨑 U+FA11 is itself unified.
+	if got := n.Normalize("\uF91F\uFA10\uF9DC\uFA11").Text; got != "\u862D\u585A\u9686\uFA11" {
+		t.Errorf("Text = %+q", got)
+	}
+}
@@ -0,0 +1,120 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Sentence is a sentence of a text with rune offsets [Start, End).
+type Sentence struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+const (
+	// sentenceTerminators always end a sentence; '.' and "..." are decided by context.
+	sentenceTerminators = "。！？；!?…｡"
+	// sentenceClosers are kept with the sentence they close: 他说：“走吧！”
+	sentenceClosers = "”’」』）)】》〉〕］]"
+	// asciiQuotes open and close alike; one closes the sentence only when the sentence
+	// has an unmatched one: He said "go." / 好。"明天见"
+	asciiQuotes = "\"'"
+)
+
+// abbreviations end with a '.' that does not end a sentence (lowercase, without the dot).
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true,
+	"st": true, "inc": true, "ltd": true, "co": true, "corp": true, "no": true,
+	"vs": true, "etc": true, "e.g": true, "i.e": true, "u.s": true, "a.m": true, "p.m": true,
+}
+
+// SplitSentences splits text at 。！？；…, at sentence-final '.' and at line breaks.
+// Repeated terminators (！？, ……) and the closing quotes or brackets that follow them stay
+// with the sentence. A '.' inside a number (3.14), a domain (www.baidu.com), an initial (J.)
+// or an abbreviation (Dr.) does not end a sentence. Surrounding whitespace is trimmed and
+// the offsets point at the trimmed sentence in text.
+func SplitSentences(text string) []Sentence {
+	runes := []rune(text)
+	var sentences []Sentence
+	emit := func(start, end int) {
+		for start < end && unicode.IsSpace(runes[start]) {
+			start++
+		}
+		for end > start && unicode.IsSpace(runes[end-1]) {
+			end--
+		}
+		if start < end {
+			sentences = append(sentences, Sentence{Text: string(runes[start:end]), Start: start, End: end})
+		}
+	}
+
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\n' || r == '\r' {
+			emit(start, i)
+			start = i + 1
+			continue
+		}
+		if !strings.ContainsRune(sentenceTerminators, r) && !(r == '.' && endsWithPeriod(runes, i)) {
+			continue
+		}
+		// Absorb following terminators and closing quotes: "……", "？！", "。”", "...)".
+		end := i + 1
+		for end < len(runes) && (strings.ContainsRune(sentenceTerminators, runes[end]) || runes[end] == '.') {
+			end++
+		}
+		for end < len(runes) && closes(runes, start, end) {
+			end++
+		}
+		emit(start, end)
+		start = end
+		i = end - 1
+	}
+	emit(start, len(runes))
+	return sentences
+}
+
+// endsWithPeriod reports whether the '.' at i ends a sentence.
+func endsWithPeriod(runes []rune, i int) bool {
+	// "..." is an ellipsis; decide at its last dot.
+	if i+1 < len(runes) && runes[i+1] == '.' {
+		return false
+	}
+	// The next character must not continue a word or number: 3.14, www.baidu.com.
+	if i+1 < len(runes) {
+		next := runes[i+1]
+		if !unicode.IsSpace(next) && !unicode.Is(unicode.Han, next) && !strings.ContainsRune(sentenceClosers+asciiQuotes, next) {
+			return false
+		}
+	}
+	// The word before the dot must not be an initial or an abbreviation: J. K. Rowling, Dr. Li.
+	j := i
+	for j > 0 && (unicode.IsLetter(runes[j-1]) || runes[j-1] == '.') && !unicode.Is(unicode.Han, runes[j-1]) {
+		j--
+	}
+	word := string(runes[j:i])
+	if len([]rune(word)) == 1 && unicode.IsUpper(runes[j]) {
+		return false
+	}
+	return !abbreviations[strings.ToLower(word)]
+}
+
+// closes reports whether runes[i] closes the sentence starting at start.
+func closes(runes []rune, start, i int) bool {
+	r := runes[i]
+	if strings.ContainsRune(sentenceClosers, r) {
+		return true
+	}
+	if !strings.ContainsRune(asciiQuotes, r) {
+		return false
+	}
+	open := false
+	for _, c := range runes[start:i] {
+		if c == r {
+			open = !open
+		}
+	}
+	return open
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"今天天气很好。我们去公园吧！好不好？", []string{"今天天气很好。", "我们去公园吧！", "好不好？"}},
+		{"他说：“走吧！”然后离开了。", []string{"他说：“走吧！”", "然后离开了。"}},
+		{"真的吗？！不会吧……我不信", []string{"真的吗？！", "不会吧……", "我不信"}},
+		{"价格是3.14元。访问www.baidu.com查看", []string{"价格是3.14元。", "访问www.baidu.com查看"}},
+		{"Dr. Li arrived at 3 p.m. today. He left.", []string{"Dr. Li arrived at 3 p.m. today.", "He left."}},
+		{"J. K. Rowling wrote it... Then what?", []string{"J. K. Rowling wrote it...", "Then what?"}},
+		{"第一行\n第二行；第三行", []string{"第一行", "第二行；", "第三行"}},
+		{`他说："走吧！"然后离开了。`, []string{`他说："走吧！"`, "然后离开了。"}},
+		{`好。"明天见。"`, []string{"好。", `"明天见。"`}},
+		{`He said "go." Then he left.`, []string{`He said "go."`, "Then he left."}},
+		{"含早; 免费WiFi; for (i = 0; i < n; i++)。", []string{"含早; 免费WiFi; for (i = 0; i < n; i++)。"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, s := range SplitSentences(tt.input) {
+			got = append(got, s.Text)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("SplitSentences(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSplitSentences_Offsets(t *testing.T) {
+	got := SplitSentences("  你好。 世界！")
+	expected := []Sentence{
+		{Text: "你好。", Start: 2, End: 5},
+		{Text: "世界！", Start: 6, End: 9},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("SplitSentences() = %v, want %v", got, expected)
+	}
+}
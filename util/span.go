@@ -0,0 +1,36 @@
+package util
+
+import "sort"
+
+// SelectLongest keeps the longest of overlapping spans, where span returns the rune offsets
+// [start, end) of an item; ties go to the earlier item. The result is ordered by start.
+func SelectLongest[T any](items []T, span func(T) (start, end int)) []T {
+	length := func(t T) int {
+		start, end := span(t)
+		return end - start
+	}
+	byLength := append([]T(nil), items...)
+	sort.SliceStable(byLength, func(i, j int) bool {
+		return length(byLength[i]) > length(byLength[j])
+	})
+	var kept []T
+	for _, c := range byLength {
+		cs, ce := span(c)
+		overlaps := false
+		for _, k := range kept {
+			if ks, ke := span(k); cs < ke && ks < ce {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			kept = append(kept, c)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		a, _ := span(kept[i])
+		b, _ := span(kept[j])
+		return a < b
+	})
+	return kept
+}
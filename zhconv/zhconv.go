@@ -0,0 +1,179 @@
+// Package zhconv converts between simplified and traditional Chinese with embedded
+// character and phrase tables. Conversion is character for character, so the output
+// has the same number of runes as the input and offsets carry over unchanged.
+package zhconv
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	//go:embed chars.txt
+	charsData string
+	//go:embed phrases.txt
+	phrasesData string
+)
+
+// Converter holds the conversion tables of both directions.
+type Converter struct {
+	s2t, t2s               map[rune]rune
+	s2tPhrases, t2sPhrases map[string]string
+	maxPhrase              int // longest phrase in runes
+}
+
+var (
+	defaultOnce sync.Once
+	defaultConv *Converter
+)
+
+// Default returns the converter built from the tables embedded in the package.
+func Default() *Converter {
+	defaultOnce.Do(func() {
+		c, err := Load(strings.NewReader(charsData), strings.NewReader(phrasesData))
+		if err != nil {
+			panic(err)
+		}
+		defaultConv = c
+	})
+	return defaultConv
+}
+
+// ToSimplified converts text to simplified Chinese with the default tables.
+func ToSimplified(text string) string {
+	return Default().ToSimplified(text)
+}
+
+// ToTraditional converts text to traditional Chinese with the default tables.
+func ToTraditional(text string) string {
+	return Default().ToTraditional(text)
+}
+
+// Load reads a character table with lines of "简 繁 [异体...]" and a phrase table with
+// lines of "简体词 繁體詞". The first traditional form is used for simplified to traditional;
+// every form converts back. Phrases settle the characters with several counterparts
+// (头发 -> 頭髮, 以后 -> 以後) and must have the same length on both sides.
+func Load(chars, phrases io.Reader) (*Converter, error) {
+	c := &Converter{
+		s2t:        make(map[rune]rune),
+		t2s:        make(map[rune]rune),
+		s2tPhrases: make(map[string]string),
+		t2sPhrases: make(map[string]string),
+	}
+	err := readTable(chars, func(lineNo int, fields []string) error {
+		simp, ok := singleRune(fields[0])
+		if !ok || len(fields) < 2 {
+			return fmt.Errorf("chars line %d: expected \"简 繁 [异体...]\"", lineNo)
+		}
+		for i, f := range fields[1:] {
+			trad, ok := singleRune(f)
+			if !ok {
+				return fmt.Errorf("chars line %d: %q is not a single character", lineNo, f)
+			}
+			if i == 0 {
+				c.s2t[simp] = trad
+			}
+			c.t2s[trad] = simp
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if phrases == nil {
+		return c, nil
+	}
+	err = readTable(phrases, func(lineNo int, fields []string) error {
+		if len(fields) != 2 {
+			return fmt.Errorf("phrases line %d: expected \"简体词 繁體詞\"", lineNo)
+		}
+		n := utf8.RuneCountInString(fields[0])
+		if n != utf8.RuneCountInString(fields[1]) {
+			return fmt.Errorf("phrases line %d: %s and %s differ in length", lineNo, fields[0], fields[1])
+		}
+		c.s2tPhrases[fields[0]] = fields[1]
+		c.t2sPhrases[fields[1]] = fields[0]
+		if n > c.maxPhrase {
+			c.maxPhrase = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func readTable(r io.Reader, line func(lineNo int, fields []string) error) error {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if err := line(lineNo, fields); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func singleRune(s string) (rune, bool) {
+	r, size := utf8.DecodeRuneInString(s)
+	return r, size > 0 && size == len(s) && r != utf8.RuneError
+}
+
+// ToSimplified converts text to simplified Chinese (頭髮 -> 头发, 乾隆 stays 乾隆).
+func (c *Converter) ToSimplified(text string) string {
+	return c.convert(text, c.t2s, c.t2sPhrases)
+}
+
+// ToTraditional converts text to traditional Chinese (头发 -> 頭髮, 以后 -> 以後).
+func (c *Converter) ToTraditional(text string) string {
+	return c.convert(text, c.s2t, c.s2tPhrases)
+}
+
+// IsTraditional reports whether text contains a character that converts to simplified.
+func (c *Converter) IsTraditional(text string) bool {
+	for _, r := range text {
+		if s, ok := c.t2s[r]; ok && s != r {
+			return true
+		}
+	}
+	return false
+}
+
+// convert applies forward maximum matching over the phrase table and falls back to
+// the character table.
+func (c *Converter) convert(text string, chars map[rune]rune, phrases map[string]string) string {
+	runes := []rune(text)
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); {
+		matched := false
+		for n := min(c.maxPhrase, len(runes)-i); n >= 2; n-- {
+			if p, ok := phrases[string(runes[i:i+n])]; ok {
+				out = append(out, []rune(p)...)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		if r, ok := chars[runes[i]]; ok {
+			out = append(out, r)
+		} else {
+			out = append(out, runes[i])
+		}
+		i++
+	}
+	return string(out)
+}
@@ -0,0 +1,65 @@
+package zhconv
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestToSimplified(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"臺灣的頭髮", "台湾的头发"},
+		{"香港維多利亞港灣酒店", "香港维多利亚港湾酒店"},
+		{"乾隆年間天氣乾燥", "乾隆年间天气干燥"},
+		{"幹部關係", "干部关系"},
+		{"hello 世界", "hello 世界"},
+	}
+	for _, tt := range tests {
+		if got := ToSimplified(tt.input); got != tt.want {
+			t.Errorf("ToSimplified(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestToTraditional(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"头发", "頭髮"},
+		{"以后发展", "以後發展"},
+		{"干净的面条", "乾淨的麵條"},
+		{"联系我们", "聯繫我們"},
+		{"皇后", "皇后"},
+	}
+	for _, tt := range tests {
+		if got := ToTraditional(tt.input); got != tt.want {
+			t.Errorf("ToTraditional(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestConvert_PreservesLength(t *testing.T) {
+	text := "頭髮乾燥，以後去臺北101看颱風"
+	got := ToSimplified(text)
+	if utf8.RuneCountInString(got) != utf8.RuneCountInString(text) {
+		t.Errorf("ToSimplified(%q) = %q changes the length", text, got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	c, err := Load(strings.NewReader("# 注释\n发 發 髮\n"), strings.NewReader("头发 頭髮\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.ToTraditional("发头发"); got != "發頭髮" {
+		t.Errorf("ToTraditional = %q, want 發頭髮", got)
+	}
+	if !c.IsTraditional("髮") || c.IsTraditional("发") {
+		t.Error("IsTraditional is wrong")
+	}
+	if _, err := Load(strings.NewReader("发 發\n"), strings.NewReader("头发 頭\n")); err == nil {
+		t.Error("expected an error for phrases of different length")
+	}
+}